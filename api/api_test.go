@@ -0,0 +1,218 @@
+package api_test
+
+import (
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zhengkyl/gol/api"
+	"github.com/zhengkyl/gol/api/client"
+	"github.com/zhengkyl/gol/game"
+)
+
+// serve starts a server in the background, returning where to dial it
+func serve(t *testing.T, network, address string) (*game.Manager, string) {
+	settings := game.DefaultSettings
+	settings.Width = 20
+	settings.Height = 20
+	settings.MaxPlacedCells = 10
+	gm := game.NewManager(nil, settings)
+
+	ln, err := api.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go api.NewServer(gm).Serve(ln)
+
+	return gm, ln.Addr().String()
+}
+
+func dial(t *testing.T, network, address string) *client.Client {
+	c, err := client.Dial(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// waitFor polls until ok or a few seconds pass, since lobbies run in real time
+func waitFor(t *testing.T, what string, ok func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !ok() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %v", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	_, address := serve(t, "unix", filepath.Join(t.TempDir(), "gol.sock"))
+	c := dial(t, "unix", address)
+
+	joined, err := c.Create("tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lobbies, err := c.Lobbies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lobbies) != 1 || lobbies[0].Id != joined.Lobby {
+		t.Fatalf("got lobbies %+v, want just %v", lobbies, joined.Lobby)
+	}
+	if joined.Width != 20 || joined.Height != 20 || joined.MaxPlacedCells != 10 {
+		t.Errorf("got %+v, want a 20x20 board with 10 cells", joined)
+	}
+
+	board, err := c.Board()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.Count(joined.Player) != 0 {
+		t.Error("new player already has cells")
+	}
+
+	// A blinker stays 3 cells
+	blinker := [][2]int{{9, 10}, {10, 10}, {11, 10}}
+	p, err := c.Place(blinker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Placed != 3 {
+		t.Errorf("placed %v cells, want 3", p.Placed)
+	}
+
+	diff, err := c.Diff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Cells) != 3 {
+		t.Fatalf("got diff %+v, want the 3 placed cells", diff.Cells)
+	}
+	for _, cell := range diff.Cells {
+		if cell.Paused != joined.Player || cell.Player != 0 {
+			t.Errorf("got %+v, want a paused cell", cell)
+		}
+	}
+
+	p, err = c.Toggle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Paused {
+		t.Fatal("still paused after toggling")
+	}
+	if _, err := c.Place([][2]int{{0, 0}}); err == nil {
+		t.Error("placed a cell while playing")
+	}
+
+	start := board.Generation
+	waitFor(t, "a generation", func() bool {
+		if err := c.Update(board); err != nil {
+			t.Fatal(err)
+		}
+		return board.Generation > start+1
+	})
+	if n := board.Count(joined.Player); n != 3 {
+		t.Errorf("blinker has %v cells, want 3", n)
+	}
+
+	players, err := c.Players()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 1 || players[0].Name != "tester" || players[0].Cells != 3 {
+		t.Errorf("got players %+v", players)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	gm, address := serve(t, "tcp", "127.0.0.1:0")
+	c := dial(t, "tcp", address)
+
+	if _, err := c.Board(); err == nil || !strings.Contains(err.Error(), "join") {
+		t.Errorf("got %v reading a board outside a lobby", err)
+	}
+	if _, err := c.Create("not a name"); err == nil {
+		t.Error("created a lobby with an invalid name")
+	}
+	if infos := gm.LobbyInfos(); len(infos) != 0 {
+		t.Errorf("a failed create left lobbies %+v", infos)
+	}
+	if _, err := c.Join(42, "tester"); err == nil {
+		t.Error("joined a lobby that doesn't exist")
+	}
+	joined, err := c.Create("tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Join(joined.Lobby, "tester"); err == nil {
+		t.Error("joined the same lobby twice")
+	}
+	if _, err := c.Place([][2]int{{20, 0}}); err == nil {
+		t.Error("placed a cell off the board")
+	}
+
+	// Errors don't end the connection
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	raw := client.New(conn)
+	if _, err := conn.Write([]byte("not json\n")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(buf[:n]), "bad request") {
+		t.Errorf("got %q", buf[:n])
+	}
+	if _, err := raw.Lobbies(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDisconnectLeaves(t *testing.T) {
+	gm, address := serve(t, "tcp", "127.0.0.1:0")
+	host := dial(t, "tcp", address)
+	guest := dial(t, "tcp", address)
+
+	joined, err := host.Create("host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := guest.Join(joined.Lobby, "guest"); err != nil {
+		t.Fatal(err)
+	}
+
+	players, err := host.Players()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 2 {
+		t.Fatalf("got players %+v, want host and guest", players)
+	}
+
+	guest.Close()
+	waitFor(t, "the guest to leave", func() bool {
+		players, err := host.Players()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(players) == 1
+	})
+
+	host.Close()
+	waitFor(t, "the empty lobby to close", func() bool {
+		return len(gm.LobbyInfos()) == 0
+	})
+}
@@ -0,0 +1,212 @@
+// Package client plays over the api, for bots written in Go
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/zhengkyl/gol/api"
+	"github.com/zhengkyl/gol/util"
+)
+
+// Client sends one request at a time, and is safe to share between goroutines
+type Client struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+	enc     *json.Encoder
+	mu      sync.Mutex
+	nextId  int
+}
+
+// Dial connects to a server's unix socket or tcp address
+func Dial(network, address string) (*Client, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return New(conn), nil
+}
+
+func New(conn net.Conn) *Client {
+	scanner := bufio.NewScanner(conn)
+	// Boards can be big
+	scanner.Buffer(make([]byte, 4096), 16<<20)
+	return &Client{
+		conn:    conn,
+		scanner: scanner,
+		enc:     json.NewEncoder(conn),
+	}
+}
+
+// Close ends the connection. The server takes the player out of their lobby
+// when it notices.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// call sends a request and decodes its result into out, unless out is nil
+func (c *Client) call(req api.Request, out any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextId++
+	req.Id = c.nextId
+	if err := c.enc.Encode(req); err != nil {
+		return err
+	}
+
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return err
+		}
+		return errors.New("connection closed")
+	}
+	var res api.Response
+	if err := json.Unmarshal(c.scanner.Bytes(), &res); err != nil {
+		return err
+	}
+	if res.Id != req.Id {
+		return fmt.Errorf("got response %v to request %v", res.Id, req.Id)
+	}
+	if res.Error != "" {
+		return errors.New(res.Error)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(res.Result, out)
+}
+
+func (c *Client) Lobbies() ([]api.Lobby, error) {
+	var lobbies []api.Lobby
+	err := c.call(api.Request{Op: api.OpLobbies}, &lobbies)
+	return lobbies, err
+}
+
+// Create leaves the current lobby, if any, for a new one. name is only used
+// the first time.
+func (c *Client) Create(name string) (api.Joined, error) {
+	var joined api.Joined
+	err := c.call(api.Request{Op: api.OpCreate, Name: name}, &joined)
+	return joined, err
+}
+
+// Join leaves the current lobby, if any, for another. name is only used the
+// first time.
+func (c *Client) Join(lobby int, name string) (api.Joined, error) {
+	var joined api.Joined
+	err := c.call(api.Request{Op: api.OpJoin, Lobby: lobby, Name: name}, &joined)
+	return joined, err
+}
+
+func (c *Client) Leave() error {
+	return c.call(api.Request{Op: api.OpLeave}, nil)
+}
+
+func (c *Client) Players() ([]api.Player, error) {
+	var players []api.Player
+	err := c.call(api.Request{Op: api.OpPlayers}, &players)
+	return players, err
+}
+
+// Place puts paused cells down while editing, up to the lobby's limit. It
+// returns the player afterwards, to see how many were placed.
+func (c *Client) Place(cells [][2]int) (api.Player, error) {
+	var p api.Player
+	err := c.call(api.Request{Op: api.OpPlace, Cells: cells}, &p)
+	return p, err
+}
+
+// Remove takes paused cells back while editing
+func (c *Client) Remove(cells [][2]int) (api.Player, error) {
+	var p api.Player
+	err := c.call(api.Request{Op: api.OpRemove, Cells: cells}, &p)
+	return p, err
+}
+
+// Toggle starts playing the paused cells, or goes back to editing. Playing
+// fails if a paused cell is on a live one, which leaves the player paused.
+func (c *Client) Toggle() (api.Player, error) {
+	var p api.Player
+	err := c.call(api.Request{Op: api.OpToggle}, &p)
+	return p, err
+}
+
+// Board fetches the whole board
+func (c *Client) Board() (*Board, error) {
+	var b api.Board
+	if err := c.call(api.Request{Op: api.OpBoard}, &b); err != nil {
+		return nil, err
+	}
+
+	board := &Board{
+		Generation: b.Generation,
+		Width:      b.Width,
+		Height:     b.Height,
+		cells:      make([][]api.Cell, b.Height),
+	}
+	for y := range board.cells {
+		board.cells[y] = make([]api.Cell, b.Width)
+		for x := range board.cells[y] {
+			board.cells[y][x] = api.Cell{X: x, Y: y}
+		}
+	}
+	board.apply(b.Cells)
+	return board, nil
+}
+
+// Diff fetches what changed since the last Board, Diff or Update
+func (c *Client) Diff() (api.Diff, error) {
+	var d api.Diff
+	err := c.call(api.Request{Op: api.OpDiff}, &d)
+	return d, err
+}
+
+// Update brings a board from Board up to date, sending only what changed
+func (c *Client) Update(b *Board) error {
+	d, err := c.Diff()
+	if err != nil {
+		return err
+	}
+	b.Generation = d.Generation
+	b.apply(d.Cells)
+	return nil
+}
+
+// Board is a copy of a lobby's board, kept up to date with Update
+type Board struct {
+	Generation int
+	Width      int
+	Height     int
+	cells      [][]api.Cell
+}
+
+func (b *Board) apply(cells []api.Cell) {
+	for _, c := range cells {
+		if c.X >= 0 && c.X < b.Width && c.Y >= 0 && c.Y < b.Height {
+			b.cells[c.Y][c.X] = c
+		}
+	}
+}
+
+// At returns a cell, wrapping around the edges like the board does
+func (b *Board) At(x, y int) api.Cell {
+	return b.cells[util.Mod(y, b.Height)][util.Mod(x, b.Width)]
+}
+
+// Count returns how many live cells a player owns
+func (b *Board) Count(player int) int {
+	n := 0
+	for _, row := range b.cells {
+		for _, c := range row {
+			if c.Player == player {
+				n++
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,101 @@
+// Package api lets programs play over a socket, one JSON object per line.
+//
+// Every request gets exactly one response with the same id, in order:
+//
+//	{"id":1,"op":"join","lobby":3,"name":"mybot"}
+//	{"id":1,"result":{"player":7,"lobby":3,"width":160,"height":90,"max_placed_cells":50}}
+//
+// Failed requests have an error instead of a result. Ops are:
+//
+//	lobbies          list lobbies
+//	create           make a lobby and join it as name
+//	join             join lobby as name
+//	leave            go back to the menu
+//	players          everyone in the lobby
+//	board            every cell that isn't dead
+//	diff             cells that changed since the last board or diff
+//	place, remove    paused cells, while editing
+//	toggle           start playing, or go back to editing
+//
+// Names are only used by the first create or join. Anyone can claim any name
+// here, so players are guests, whose stats aren't saved.
+package api
+
+import "encoding/json"
+
+const (
+	OpLobbies = "lobbies"
+	OpCreate  = "create"
+	OpJoin    = "join"
+	OpLeave   = "leave"
+	OpPlayers = "players"
+	OpBoard   = "board"
+	OpDiff    = "diff"
+	OpPlace   = "place"
+	OpRemove  = "remove"
+	OpToggle  = "toggle"
+)
+
+type Request struct {
+	Id    int      `json:"id"`
+	Op    string   `json:"op"`
+	Lobby int      `json:"lobby,omitempty"`
+	Name  string   `json:"name,omitempty"`
+	Cells [][2]int `json:"cells,omitempty"`
+}
+
+type Response struct {
+	Id     int             `json:"id"`
+	Error  string          `json:"error,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+}
+
+type Lobby struct {
+	Id         int      `json:"id"`
+	Name       string   `json:"name"`
+	Players    []string `json:"players"`
+	MaxPlayers int      `json:"max_players"`
+	Persistent bool     `json:"persistent"`
+}
+
+type Joined struct {
+	Player         int `json:"player"`
+	Lobby          int `json:"lobby"`
+	Width          int `json:"width"`
+	Height         int `json:"height"`
+	MaxPlacedCells int `json:"max_placed_cells"`
+}
+
+type Player struct {
+	Id     int    `json:"id"`
+	Name   string `json:"name"`
+	Color  int    `json:"color"`
+	Paused bool   `json:"paused"`
+	// Paused cells
+	Placed int `json:"placed"`
+	// Live cells as of the last generation
+	Cells int  `json:"cells"`
+	Bot   bool `json:"bot,omitempty"`
+}
+
+// Cell is owned by a player, and may have someone's paused cell on it too.
+// Ids are 0 for neither, and -1 for live cells that belong to no one.
+type Cell struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Player int `json:"player"`
+	Paused int `json:"paused"`
+}
+
+type Board struct {
+	Generation int    `json:"generation"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	Cells      []Cell `json:"cells"`
+}
+
+// Diff has every cell that changed, including ones that died
+type Diff struct {
+	Generation int    `json:"generation"`
+	Cells      []Cell `json:"cells"`
+}
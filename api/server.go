@@ -0,0 +1,321 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/charmbracelet/log"
+	"github.com/zhengkyl/gol/game"
+	"github.com/zhengkyl/gol/game/life"
+	"github.com/zhengkyl/gol/store"
+)
+
+// Longest request line, enough to place a whole board
+const maxLine = 1 << 20
+
+type Server struct {
+	gm *game.Manager
+}
+
+func NewServer(gm *game.Manager) *Server {
+	return &Server{gm}
+}
+
+// Listen opens a unix socket or tcp address. A socket left behind by a server
+// that didn't stop cleanly is removed first.
+func Listen(network, address string) (net.Listener, error) {
+	if network == "unix" {
+		if info, err := os.Stat(address); err == nil && info.Mode()&os.ModeSocket != 0 {
+			if err := os.Remove(address); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return net.Listen(network, address)
+}
+
+// Serve handles connections until ln is closed
+func (s *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// session is one connection, which plays as at most one player
+type session struct {
+	gm       *game.Manager
+	playerId int
+	lobbyId  int
+	lobby    *game.Lobby
+	// Diffs are against the last board sent
+	last [][]life.Cell
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	ss := &session{gm: s.gm}
+	defer ss.close()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 4096), maxLine)
+	enc := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var req Request
+		var res Response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			res.Error = fmt.Sprintf("bad request: %v", err)
+		} else {
+			res = ss.handle(req)
+		}
+		if err := enc.Encode(res); err != nil {
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Debug("api connection closed", "err", err)
+	}
+}
+
+func (ss *session) close() {
+	if ss.playerId == 0 {
+		return
+	}
+	// Guests' spots aren't held, so this leaves the lobby too
+	ss.gm.Disconnect(ss.playerId)
+}
+
+func (ss *session) handle(req Request) Response {
+	res := Response{Id: req.Id}
+
+	result, err := ss.do(req)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if res.Result, err = json.Marshal(result); err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+func (ss *session) do(req Request) (any, error) {
+	switch req.Op {
+	case OpLobbies:
+		return ss.lobbies(), nil
+	case OpCreate:
+		return ss.create(req.Name)
+	case OpJoin:
+		return ss.join(req.Lobby, req.Name)
+	}
+
+	if ss.lobby == nil {
+		if isOp(req.Op) {
+			return nil, errors.New("join a lobby first")
+		}
+		return nil, fmt.Errorf("unknown op %q", req.Op)
+	}
+
+	switch req.Op {
+	case OpLeave:
+		ss.leave()
+		return struct{}{}, nil
+	case OpPlayers:
+		return ss.players(), nil
+	case OpBoard:
+		return ss.board(), nil
+	case OpDiff:
+		return ss.diff(), nil
+	case OpPlace, OpRemove:
+		return ss.paint(req.Cells, req.Op == OpPlace)
+	case OpToggle:
+		ss.lobby.TogglePause(ss.playerId)
+		return ss.self(), nil
+	}
+	return nil, fmt.Errorf("unknown op %q", req.Op)
+}
+
+func isOp(op string) bool {
+	switch op {
+	case OpLeave, OpPlayers, OpBoard, OpDiff, OpPlace, OpRemove, OpToggle:
+		return true
+	}
+	return false
+}
+
+func (ss *session) lobbies() []Lobby {
+	lobbies := []Lobby{}
+	for _, info := range ss.gm.LobbyInfos() {
+		lobbies = append(lobbies, Lobby{
+			Id:         info.Id,
+			Name:       info.Name,
+			Players:    info.PlayerNames,
+			MaxPlayers: info.MaxPlayers,
+			Persistent: info.Persistent,
+		})
+	}
+	return lobbies
+}
+
+// connect makes the session's player the first time, as a guest, since
+// anyone can claim any name here. Guests aren't saved and can't reconnect.
+func (ss *session) connect(name string) error {
+	if ss.playerId != 0 {
+		return nil
+	}
+	if err := store.ValidateNickname(name); err != nil {
+		return err
+	}
+	// Updates are never drawn, so the client is never started
+	ss.playerId = ss.gm.Connect(game.NewClient(), "", name)
+	return nil
+}
+
+func (ss *session) leave() {
+	if ss.lobby == nil {
+		return
+	}
+	ss.gm.LeaveLobby(ss.playerId)
+	ss.lobby = nil
+	ss.last = nil
+}
+
+// create joins a new lobby, so there's never one that no one is in
+func (ss *session) create(name string) (any, error) {
+	if err := ss.connect(name); err != nil {
+		return nil, err
+	}
+	lobbyId, err := ss.gm.CreateLobby()
+	if err != nil {
+		return nil, err
+	}
+	ss.leave()
+	return ss.enter(lobbyId)
+}
+
+func (ss *session) join(lobbyId int, name string) (any, error) {
+	if err := ss.connect(name); err != nil {
+		return nil, err
+	}
+	if ss.lobby != nil {
+		// Leaving could close the lobby before it's joined again
+		if lobbyId == ss.lobbyId {
+			return nil, fmt.Errorf("already in lobby %v", lobbyId)
+		}
+		ss.leave()
+	}
+	return ss.enter(lobbyId)
+}
+
+func (ss *session) enter(lobbyId int) (any, error) {
+	switch msg := ss.gm.JoinLobby(lobbyId, ss.playerId).(type) {
+	case game.JoinSuccessMsg:
+		ss.lobby = msg.Lobby
+		ss.lobbyId = lobbyId
+		return Joined{
+			Player:         ss.playerId,
+			Lobby:          lobbyId,
+			Width:          msg.BoardWidth,
+			Height:         msg.BoardHeight,
+			MaxPlacedCells: msg.Lobby.Settings().MaxPlacedCells,
+		}, nil
+	case game.JoinFailMsg:
+		return nil, msg
+	}
+	return nil, errors.New("could not join")
+}
+
+func player(p game.PlayerState) Player {
+	return Player{
+		Id:     p.Id,
+		Name:   p.Name,
+		Color:  p.Color,
+		Paused: p.Paused,
+		Placed: p.Placed,
+		Cells:  p.Cells,
+		Bot:    p.Bot,
+	}
+}
+
+func (ss *session) players() []Player {
+	players := []Player{}
+	for _, p := range ss.lobby.Players() {
+		players = append(players, player(p))
+	}
+	return players
+}
+
+func (ss *session) self() Player {
+	for _, p := range ss.lobby.Players() {
+		if p.Id == ss.playerId {
+			return player(p)
+		}
+	}
+	return Player{Id: ss.playerId}
+}
+
+func (ss *session) board() Board {
+	generation, board := ss.lobby.Board()
+	ss.last = board
+
+	cells := []Cell{}
+	for y, row := range board {
+		for x, c := range row {
+			if c != (life.Cell{}) {
+				cells = append(cells, Cell{x, y, c.Player, c.PausedPlayer})
+			}
+		}
+	}
+	return Board{generation, len(board[0]), len(board), cells}
+}
+
+// diff is every cell that isn't dead if there was no last board
+func (ss *session) diff() Diff {
+	generation, board := ss.lobby.Board()
+	last := ss.last
+	ss.last = board
+
+	cells := []Cell{}
+	for y, row := range board {
+		for x, c := range row {
+			var prev life.Cell
+			if last != nil {
+				prev = last[y][x]
+			}
+			if c != prev {
+				cells = append(cells, Cell{x, y, c.Player, c.PausedPlayer})
+			}
+		}
+	}
+	return Diff{generation, cells}
+}
+
+func (ss *session) paint(cells [][2]int, alive bool) (any, error) {
+	if p := ss.self(); !p.Paused {
+		return nil, errors.New("cells can only be placed while editing")
+	}
+
+	width, height := ss.lobby.BoardSize()
+	for _, c := range cells {
+		if c[0] < 0 || c[0] >= width || c[1] < 0 || c[1] >= height {
+			return nil, fmt.Errorf("cell (%v, %v) is off the %vx%v board", c[0], c[1], width, height)
+		}
+	}
+	for _, c := range cells {
+		ss.lobby.MoveCursor(ss.playerId, c[0], c[1])
+		ss.lobby.Paint(ss.playerId, alive)
+	}
+	return ss.self(), nil
+}
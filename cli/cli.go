@@ -0,0 +1,68 @@
+// Package cli has the subcommands that don't start the server
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zhengkyl/gol/game/pattern"
+)
+
+// readPattern loads a pattern file, or stdin for "-". The format is guessed
+// from the extension unless given.
+func readPattern(path, format string, stdin io.Reader) (*pattern.Pattern, error) {
+	if format == "" {
+		if path == "-" {
+			return nil, fmt.Errorf("the format of stdin must be given")
+		}
+		var err error
+		if format, err = pattern.FormatFor(path); err != nil {
+			return nil, err
+		}
+	}
+
+	r := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	p, err := pattern.Read(r, format)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", path, err)
+	}
+	return p, nil
+}
+
+// writePattern saves a pattern file, or writes to stdout for "-"
+func writePattern(path, format string, p *pattern.Pattern, stdout io.Writer) error {
+	if format == "" {
+		if path == "-" {
+			format = "rle"
+		} else {
+			var err error
+			if format, err = pattern.FormatFor(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if path == "-" {
+		return pattern.Write(stdout, p, format)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pattern.Write(f, p, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const gliderRLE = "#N Glider\nx = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n"
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "glider.rle")
+	if err := os.WriteFile(in, []byte(gliderRLE), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out.cells")
+
+	sb := strings.Builder{}
+	err := Run([]string{"-n", "8", "-every", "4", "-width", "10", "-height", "10", "-o", out, in}, nil, &sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"B3/S23 on a 10x10 torus", "gen 4: 5 cells", "gen 8: 5 cells", "objects: 1 glider"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("output missing %q:\n%v", want, sb.String())
+		}
+	}
+
+	// The glider started at 3, 3 and moved 2 cells down and right. Empty
+	// rows are kept, so the board is the same size.
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "!Name: Glider\n\n\n\n\n\n......O\n.......O\n.....OOO\n\n\n"
+	if string(b) != want {
+		t.Errorf("wrote %q, want %q", b, want)
+	}
+}
+
+func TestRunPlane(t *testing.T) {
+	sb := strings.Builder{}
+	// The glider leaves the board and turns into a block at the corner
+	err := Run([]string{"-n", "40", "-width", "5", "-height", "5", "-topology", "plane", "-from", "rle", "-"}, strings.NewReader(gliderRLE), &sb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "objects: 1 block") {
+		t.Errorf("glider didn't end as a block:\n%v", sb.String())
+	}
+}
+
+func TestRunSize(t *testing.T) {
+	for _, args := range [][]string{{"-width", "-1"}, {"-height", "0"}} {
+		args = append(args, "-from", "rle", "-")
+		if err := Run(args, strings.NewReader("x = 0, y = 0\n!\n"), io.Discard); err == nil {
+			t.Errorf("%v ran", args)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	sb := strings.Builder{}
+	if err := Convert([]string{"-from", "rle", "-to", "life106", "-"}, strings.NewReader(gliderRLE), &sb); err != nil {
+		t.Fatal(err)
+	}
+	want := "#Life 1.06\n#N Glider\n1 0\n2 1\n0 2\n1 2\n2 2\n"
+	if sb.String() != want {
+		t.Errorf("got %q, want %q", sb.String(), want)
+	}
+}
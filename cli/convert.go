@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zhengkyl/gol/game/pattern"
+)
+
+// Convert translates a pattern file between formats
+//
+//	gol convert [flags] input [output]
+func Convert(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gol convert", flag.ContinueOnError)
+	from := fs.String("from", "", "input format, guessed from the extension by default")
+	to := fs.String("to", "", "output format, guessed from the extension by default")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: gol convert [flags] input [output]\n\nFormats are %v. Use - for stdin or stdout.\n\n", strings.Join(pattern.Formats, ", "))
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return fmt.Errorf("usage: gol convert [flags] input [output]")
+	}
+
+	p, err := readPattern(fs.Arg(0), *from, stdin)
+	if err != nil {
+		return err
+	}
+
+	out := "-"
+	if fs.NArg() == 2 {
+		out = fs.Arg(1)
+	}
+	return writePattern(out, *to, p, stdout)
+}
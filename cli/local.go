@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	petname "github.com/dustinkirkland/golang-petname"
+	"github.com/zhengkyl/gol/game"
+	"github.com/zhengkyl/gol/store"
+	"github.com/zhengkyl/gol/ui"
+)
+
+// Local plays on this terminal, with lobbies only this process can join
+//
+//	gol local [flags]
+func Local(args []string) error {
+	fs := flag.NewFlagSet("gol local", flag.ContinueOnError)
+	dataDir := fs.String("data-dir", "", "directory to keep settings, stats and worlds in, or nothing to forget them")
+	nickname := fs.String("nickname", os.Getenv("USER"), "name shown to bots")
+	difficulty := fs.String("difficulty", game.DefaultSettings.BotDifficulty.String(), "how well bots play: "+strings.Join(game.Difficulties, ", "))
+	bots := fs.Int("bots", game.DefaultSettings.MaxBots, "most bots a lobby can be filled with")
+	logPath := fs.String("log", "", "file to log to, since the terminal is in use")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("usage: gol local [flags]")
+	}
+
+	settings := game.DefaultSettings
+	var err error
+	if settings.BotDifficulty, err = game.ParseDifficulty(*difficulty); err != nil {
+		return err
+	}
+	if *bots < 0 || *bots > game.MaxPlayers {
+		return fmt.Errorf("bots must be between 0 and %v, got %v", game.MaxPlayers, *bots)
+	}
+	settings.MaxBots = *bots
+
+	log.SetOutput(io.Discard)
+	if *logPath != "" {
+		f, err := os.OpenFile(*logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		log.SetOutput(f)
+	}
+
+	var st *store.Store
+	if *dataDir != "" {
+		if st, err = store.Open(filepath.Join(*dataDir, "store.json")); err != nil {
+			return err
+		}
+	}
+	gm := game.NewManager(st, settings)
+	if *dataDir != "" {
+		if err := gm.LoadWorlds(filepath.Join(*dataDir, "worlds")); err != nil {
+			return err
+		}
+	}
+
+	if store.ValidateNickname(*nickname) != nil {
+		*nickname = petname.Generate(2, "-")
+		if len(*nickname) > store.MaxNicknameLength {
+			*nickname = (*nickname)[:store.MaxNicknameLength]
+		}
+	}
+
+	// The size is sent once the program starts
+	model := ui.New(0, 0, gm, lipgloss.NewRenderer(os.Stdout))
+	client := game.NewClient()
+	// Output isn't wrapped to measure latency, since the program needs the
+	// terminal itself to know its size
+	p := tea.NewProgram(&model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	client.Start(p)
+
+	playerId := gm.Connect(client, "local:"+*nickname, *nickname)
+	go func() {
+		p.Send(ui.PlayerId(playerId))
+	}()
+
+	_, err = p.Run()
+	gm.Disconnect(playerId)
+
+	// Only worlds loaded from a data dir are saved
+	if saveErr := gm.Close(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	if st != nil {
+		if saveErr := st.Close(); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+	return err
+}
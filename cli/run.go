@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/zhengkyl/gol/game/analysis"
+	"github.com/zhengkyl/gol/game/life"
+	"github.com/zhengkyl/gol/game/pattern"
+)
+
+// Room left around a pattern when the board size isn't given
+const runMargin = 16
+
+// Run steps a pattern file for some generations and reports its population
+//
+//	gol run [flags] pattern
+func Run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gol run", flag.ContinueOnError)
+	generations := fs.Int("n", 100, "generations to run")
+	ruleFlag := fs.String("rule", "", "rule like B3/S23, defaults to the pattern's or B3/S23")
+	topologyFlag := fs.String("topology", "torus", "torus or plane")
+	width := fs.Int("width", 0, "board width, defaults to the pattern's plus a margin")
+	height := fs.Int("height", 0, "board height, defaults to the pattern's plus a margin")
+	every := fs.Int("every", 0, "print the population every this many generations")
+	from := fs.String("from", "", "pattern format, guessed from the extension by default")
+	out := fs.String("o", "", "write the final board to this file, or - for stdout")
+	to := fs.String("to", "", "format of -o, guessed from the extension by default")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: gol run [flags] pattern")
+	}
+	if *generations < 0 {
+		return fmt.Errorf("-n can't be negative, got %v", *generations)
+	}
+	var sizeErr error
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "width" && *width <= 0 || f.Name == "height" && *height <= 0 {
+			sizeErr = fmt.Errorf("-%v must be positive, got %v", f.Name, f.Value)
+		}
+	})
+	if sizeErr != nil {
+		return sizeErr
+	}
+
+	p, err := readPattern(fs.Arg(0), *from, stdin)
+	if err != nil {
+		return err
+	}
+
+	rule := life.Conway
+	ruleName := *ruleFlag
+	if ruleName == "" {
+		ruleName = p.Rule
+	}
+	if ruleName != "" {
+		if rule, err = life.ParseRule(ruleName); err != nil {
+			return err
+		}
+	}
+	topology, err := life.ParseTopology(*topologyFlag)
+	if err != nil {
+		return err
+	}
+
+	if *width == 0 {
+		*width = p.Width + 2*runMargin
+	}
+	if *height == 0 {
+		*height = p.Height + 2*runMargin
+	}
+	if *width < p.Width || *height < p.Height {
+		return fmt.Errorf("%vx%v board is smaller than the %vx%v pattern", *width, *height, p.Width, p.Height)
+	}
+
+	board := life.NewBoard(*width, *height)
+	p.Place(board, (*width-p.Width)/2, (*height-p.Height)/2, 1)
+
+	// Only messages go to stdout when the board does
+	log := stdout
+	if *out == "-" {
+		log = io.Discard
+	}
+	fmt.Fprintf(log, "%v on a %vx%v %v\n", rule, *width, *height, topology)
+
+	population := analysis.Population(board)
+	min, max := population, population
+	tracker := analysis.NewTracker()
+	var stable *analysis.Stability
+
+	for gen := 0; ; gen++ {
+		if *every > 0 && gen%*every == 0 || gen == *generations {
+			fmt.Fprintf(log, "gen %d: %d cells\n", gen, population)
+		}
+		if stable == nil {
+			if s, ok := tracker.Add(board, gen); ok {
+				stable = &s
+			}
+		}
+		if gen == *generations {
+			break
+		}
+
+		board = life.Step(board, rule, topology)
+		population = analysis.Population(board)
+		if population < min {
+			min = population
+		}
+		if population > max {
+			max = population
+		}
+	}
+
+	fmt.Fprintf(log, "population min %d, max %d\n", min, max)
+	if stable != nil {
+		fmt.Fprintln(log, stable)
+	}
+	// The catalog only knows objects under the usual rule
+	if rule == life.Conway {
+		var counts []string
+		for _, c := range analysis.Sorted(analysis.Census(analysis.Identify(board))[1]) {
+			counts = append(counts, fmt.Sprintf("%d %s", c.N, c.Name))
+		}
+		if len(counts) > 0 {
+			fmt.Fprintf(log, "objects: %v\n", strings.Join(counts, ", "))
+		}
+	}
+
+	if *out != "" {
+		result := pattern.FromBoard(board)
+		result.Name = p.Name
+		result.Rule = rule.String()
+		return writePattern(*out, *to, result, stdout)
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zhengkyl/gol/game"
+)
+
+// Config is everything that can be changed without recompiling.
+//
+// Values are resolved in order of increasing precedence: defaults, the
+// config file, GOL_* environment variables, then command line flags.
+type Config struct {
+	Host         string   `toml:"host"`
+	Port         int      `toml:"port"`
+	HostKeyPaths []string `toml:"host_keys"`
+	// One of auto, truecolor, ansi256, ansi, ascii. auto detects each
+	// client's terminal.
+	ColorProfile string      `toml:"color_profile"`
+	DataDir      string      `toml:"data_dir"`
+	Lobby        LobbyConfig `toml:"lobby"`
+	Limits       Limits      `toml:"limits"`
+	Bots         BotConfig   `toml:"bots"`
+	API          APIConfig   `toml:"api"`
+	Log          LogConfig   `toml:"log"`
+}
+
+type LobbyConfig struct {
+	Width  int `toml:"width"`
+	Height int `toml:"height"`
+}
+
+type Limits struct {
+	MaxPlayers     int `toml:"max_players"`
+	MaxPlacedCells int `toml:"max_placed_cells"`
+	MaxLobbies     int `toml:"max_lobbies"`
+	// Persistent worlds, on top of max_lobbies
+	MaxWorlds int `toml:"max_worlds"`
+}
+
+// BotConfig is for the bots a lobby's host can fill empty spots with
+type BotConfig struct {
+	// One of easy, normal, hard
+	Difficulty string `toml:"difficulty"`
+	// Most bots in a lobby, or 0 to turn them off
+	Max int `toml:"max"`
+}
+
+// APIConfig is where programs can connect to play, see package api
+type APIConfig struct {
+	// One of unix, tcp
+	Network string `toml:"network"`
+	// Socket path or host:port, or empty to turn the api off
+	Address string `toml:"address"`
+}
+
+type LogConfig struct {
+	// One of debug, info, warn, error
+	Level string `toml:"level"`
+	// One of text, json, logfmt
+	Format string `toml:"format"`
+}
+
+func Default() Config {
+	return Config{
+		Host:         "0.0.0.0",
+		Port:         2345,
+		HostKeyPaths: []string{".ssh/server_ed25519"},
+		ColorProfile: "auto",
+		DataDir:      ".data",
+		Lobby: LobbyConfig{
+			Width:  160,
+			Height: 90,
+		},
+		Limits: Limits{
+			MaxPlayers:     game.MaxPlayers,
+			MaxPlacedCells: 50,
+			MaxLobbies:     100,
+			MaxWorlds:      10,
+		},
+		Bots: BotConfig{
+			Difficulty: game.DefaultSettings.BotDifficulty.String(),
+			Max:        game.DefaultSettings.MaxBots,
+		},
+		API: APIConfig{
+			Network: "unix",
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+}
+
+func (c Config) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// Load resolves the config for the command line args
+func Load(args []string) (Config, error) {
+	c := Default()
+
+	fs := flag.NewFlagSet("gol", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("GOL_CONFIG"), "path to a toml config file")
+	host := fs.String("host", "", "address to listen on")
+	port := fs.Int("port", 0, "port to listen on")
+	hostKey := fs.String("host-key", "", "comma separated paths to ssh host keys")
+	colorProfile := fs.String("color-profile", "", "auto, truecolor, ansi256, ansi or ascii")
+	dataDir := fs.String("data-dir", "", "directory for players and worlds")
+	width := fs.Int("lobby-width", 0, "width of new lobbies")
+	height := fs.Int("lobby-height", 0, "height of new lobbies")
+	maxPlayersFlag := fs.Int("max-players", 0, "players per lobby")
+	maxPlaced := fs.Int("max-placed-cells", 0, "cells a player can place at once")
+	maxLobbies := fs.Int("max-lobbies", 0, "lobbies at once")
+	maxWorlds := fs.Int("max-worlds", 0, "persistent worlds, or 0 for none")
+	botDifficulty := fs.String("bot-difficulty", "", strings.Join(game.Difficulties, ", "))
+	maxBots := fs.Int("max-bots", 0, "bots per lobby, or 0 for none")
+	apiNetwork := fs.String("api-network", "", "unix or tcp")
+	apiAddress := fs.String("api-address", "", "socket path or host:port for bots to connect to")
+	logLevel := fs.String("log-level", "", "debug, info, warn or error")
+	logFormat := fs.String("log-format", "", "text, json or logfmt")
+
+	if err := fs.Parse(args); err != nil {
+		return c, err
+	}
+
+	if *configPath != "" {
+		md, err := toml.DecodeFile(*configPath, &c)
+		if err != nil {
+			return c, fmt.Errorf("config file %v: %w", *configPath, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return c, fmt.Errorf("config file %v: unknown key %v", *configPath, undecoded[0])
+		}
+	}
+
+	if err := loadEnv(&c); err != nil {
+		return c, err
+	}
+
+	// Only flags that were actually passed override
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "host":
+			c.Host = *host
+		case "port":
+			c.Port = *port
+		case "host-key":
+			c.HostKeyPaths = strings.Split(*hostKey, ",")
+		case "color-profile":
+			c.ColorProfile = *colorProfile
+		case "data-dir":
+			c.DataDir = *dataDir
+		case "lobby-width":
+			c.Lobby.Width = *width
+		case "lobby-height":
+			c.Lobby.Height = *height
+		case "max-players":
+			c.Limits.MaxPlayers = *maxPlayersFlag
+		case "max-placed-cells":
+			c.Limits.MaxPlacedCells = *maxPlaced
+		case "max-lobbies":
+			c.Limits.MaxLobbies = *maxLobbies
+		case "max-worlds":
+			c.Limits.MaxWorlds = *maxWorlds
+		case "bot-difficulty":
+			c.Bots.Difficulty = *botDifficulty
+		case "max-bots":
+			c.Bots.Max = *maxBots
+		case "api-network":
+			c.API.Network = *apiNetwork
+		case "api-address":
+			c.API.Address = *apiAddress
+		case "log-level":
+			c.Log.Level = *logLevel
+		case "log-format":
+			c.Log.Format = *logFormat
+		}
+	})
+
+	return c, c.Validate()
+}
+
+func loadEnv(c *Config) error {
+	strs := map[string]*string{
+		"GOL_HOST":           &c.Host,
+		"GOL_COLOR_PROFILE":  &c.ColorProfile,
+		"GOL_DATA_DIR":       &c.DataDir,
+		"GOL_BOT_DIFFICULTY": &c.Bots.Difficulty,
+		"GOL_API_NETWORK":    &c.API.Network,
+		"GOL_API_ADDRESS":    &c.API.Address,
+		"GOL_LOG_LEVEL":      &c.Log.Level,
+		"GOL_LOG_FORMAT":     &c.Log.Format,
+	}
+	for name, s := range strs {
+		if v, ok := os.LookupEnv(name); ok {
+			*s = v
+		}
+	}
+
+	if v, ok := os.LookupEnv("GOL_HOST_KEYS"); ok {
+		c.HostKeyPaths = strings.Split(v, ",")
+	}
+
+	ints := map[string]*int{
+		"GOL_PORT":             &c.Port,
+		"GOL_LOBBY_WIDTH":      &c.Lobby.Width,
+		"GOL_LOBBY_HEIGHT":     &c.Lobby.Height,
+		"GOL_MAX_PLAYERS":      &c.Limits.MaxPlayers,
+		"GOL_MAX_PLACED_CELLS": &c.Limits.MaxPlacedCells,
+		"GOL_MAX_LOBBIES":      &c.Limits.MaxLobbies,
+		"GOL_MAX_WORLDS":       &c.Limits.MaxWorlds,
+		"GOL_MAX_BOTS":         &c.Bots.Max,
+	}
+	for name, i := range ints {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%v must be an integer, got %q", name, v)
+		}
+		*i = n
+	}
+	return nil
+}
+
+// oneOf returns a problem if value isn't valid
+func oneOf(name, value string, valid ...string) string {
+	for _, v := range valid {
+		if value == v {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%v must be one of %v, got %q", name, strings.Join(valid, ", "), value)
+}
+
+// Validate reports every problem with the config at once
+func (c Config) Validate() error {
+	var errs []string
+
+	if c.Port < 1 || c.Port > 65535 {
+		errs = append(errs, fmt.Sprintf("port must be between 1 and 65535, got %v", c.Port))
+	}
+	if len(c.HostKeyPaths) == 0 {
+		errs = append(errs, "at least one host key is required")
+	}
+	for _, p := range c.HostKeyPaths {
+		if p == "" {
+			errs = append(errs, "host key path can't be empty")
+		}
+	}
+	if problem := oneOf("color_profile", c.ColorProfile, "auto", "truecolor", "ansi256", "ansi", "ascii"); problem != "" {
+		errs = append(errs, problem)
+	}
+	if c.DataDir == "" {
+		errs = append(errs, "data_dir can't be empty")
+	}
+	if c.Lobby.Width < 8 || c.Lobby.Height < 8 {
+		errs = append(errs, fmt.Sprintf("lobby must be at least 8x8, got %vx%v", c.Lobby.Width, c.Lobby.Height))
+	}
+	if c.Limits.MaxPlayers < 1 || c.Limits.MaxPlayers > game.MaxPlayers {
+		errs = append(errs, fmt.Sprintf("max_players must be between 1 and %v, got %v", game.MaxPlayers, c.Limits.MaxPlayers))
+	}
+	if c.Limits.MaxPlacedCells < 1 {
+		errs = append(errs, fmt.Sprintf("max_placed_cells must be positive, got %v", c.Limits.MaxPlacedCells))
+	}
+	if c.Limits.MaxLobbies < 1 {
+		errs = append(errs, fmt.Sprintf("max_lobbies must be positive, got %v", c.Limits.MaxLobbies))
+	}
+	if c.Limits.MaxWorlds < 0 {
+		errs = append(errs, fmt.Sprintf("max_worlds can't be negative, got %v", c.Limits.MaxWorlds))
+	}
+	if problem := oneOf("bots.difficulty", c.Bots.Difficulty, game.Difficulties...); problem != "" {
+		errs = append(errs, problem)
+	}
+	if c.Bots.Max < 0 || c.Bots.Max > game.MaxPlayers {
+		errs = append(errs, fmt.Sprintf("bots.max must be between 0 and %v, got %v", game.MaxPlayers, c.Bots.Max))
+	}
+	if problem := oneOf("api.network", c.API.Network, "unix", "tcp"); problem != "" {
+		errs = append(errs, problem)
+	}
+	if problem := oneOf("log.level", c.Log.Level, "debug", "info", "warn", "error"); problem != "" {
+		errs = append(errs, problem)
+	}
+	if problem := oneOf("log.format", c.Log.Format, "text", "json", "logfmt"); problem != "" {
+		errs = append(errs, problem)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config:\n  %v", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zhengkyl/gol/game"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gol.toml")
+	err := os.WriteFile(path, []byte(`
+port = 1000
+color_profile = "truecolor"
+
+[lobby]
+width = 40
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GOL_PORT", "2000")
+	t.Setenv("GOL_LOBBY_WIDTH", "50")
+
+	c, err := Load([]string{"-config", path, "-lobby-width", "60"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.ColorProfile != "truecolor" {
+		t.Errorf("file: got color profile %q, want truecolor", c.ColorProfile)
+	}
+	if c.Port != 2000 {
+		t.Errorf("env: got port %v, want 2000", c.Port)
+	}
+	if c.Lobby.Width != 60 {
+		t.Errorf("flag: got width %v, want 60", c.Lobby.Width)
+	}
+	if c.Lobby.Height != Default().Lobby.Height {
+		t.Errorf("default: got height %v, want %v", c.Lobby.Height, Default().Lobby.Height)
+	}
+}
+
+func TestLoadReportsAllProblems(t *testing.T) {
+	_, err := Load([]string{"-port", "0", "-log-level", "loud"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"port", "log.level"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %v", err, want)
+		}
+	}
+}
+
+func TestLimitsFollowGame(t *testing.T) {
+	if _, err := Load([]string{"-max-players", fmt.Sprint(game.MaxPlayers)}); err != nil {
+		t.Error(err)
+	}
+	if _, err := Load([]string{"-max-players", fmt.Sprint(game.MaxPlayers + 1)}); err == nil {
+		t.Error("allowed more players than there are colors")
+	}
+	for _, d := range game.Difficulties {
+		if _, err := Load([]string{"-bot-difficulty", d}); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Package analysis finds and names the objects on a board
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/zhengkyl/gol/game/life"
+	"github.com/zhengkyl/gol/util"
+)
+
+// Point is a cell position. Positions inside an object aren't wrapped around
+// the board, so an object crossing an edge stays in one piece.
+type Point struct {
+	X, Y int
+}
+
+// Object is a group of live cells close enough to affect each other
+type Object struct {
+	// Pattern is what the object was identified as, or nil if it's unknown
+	Pattern *Pattern
+	// Player who owns most of the object's cells
+	Player int
+	// Top left corner of the object on the board
+	X, Y          int
+	Width, Height int
+	Cells         int
+}
+
+// Name is the object's pattern name, or empty if it's unknown
+func (o Object) Name() string {
+	if o.Pattern == nil {
+		return ""
+	}
+	return o.Pattern.Name
+}
+
+// reach is how far apart cells can be and still count as one object. Cells 2
+// apart share a neighbor, so they can change each other's future.
+const reach = 2
+
+// Components groups a board's live cells into objects, in the order they're
+// first reached reading the board row by row
+func Components(board [][]life.Cell) [][]Point {
+	height := len(board)
+	if height == 0 {
+		return nil
+	}
+	width := len(board[0])
+
+	seen := make([][]bool, height)
+	for y := range seen {
+		seen[y] = make([]bool, width)
+	}
+
+	var components [][]Point
+	for y := range board {
+		for x := range board[y] {
+			if seen[y][x] || board[y][x].Player == life.DeadPlayer {
+				continue
+			}
+
+			seen[y][x] = true
+			queue := []Point{{x, y}}
+			for i := 0; i < len(queue); i++ {
+				c := queue[i]
+				for dy := -reach; dy <= reach; dy++ {
+					for dx := -reach; dx <= reach; dx++ {
+						nx, ny := c.X+dx, c.Y+dy
+						bx, by := util.Mod(nx, width), util.Mod(ny, height)
+						if seen[by][bx] || board[by][bx].Player == life.DeadPlayer {
+							continue
+						}
+						seen[by][bx] = true
+						queue = append(queue, Point{nx, ny})
+					}
+				}
+			}
+			components = append(components, queue)
+		}
+	}
+	return components
+}
+
+// bounds returns the top left corner and size of points
+func bounds(points []Point) (int, int, int, int) {
+	minX, minY := points[0].X, points[0].Y
+	maxX, maxY := minX, minY
+	for _, p := range points[1:] {
+		minX = util.Min(minX, p.X)
+		minY = util.Min(minY, p.Y)
+		maxX = util.Max(maxX, p.X)
+		maxY = util.Max(maxY, p.Y)
+	}
+	return minX, minY, maxX - minX + 1, maxY - minY + 1
+}
+
+// Canonical describes the shape of points the same way however it's moved,
+// rotated or reflected
+func Canonical(points []Point) string {
+	best := ""
+	transformed := make([]Point, len(points))
+
+	// Each combination of swapping and flipping axes is one of the 8
+	// symmetries of a square
+	for i := 0; i < 8; i++ {
+		for j, p := range points {
+			x, y := p.X, p.Y
+			if i&1 != 0 {
+				x, y = y, x
+			}
+			if i&2 != 0 {
+				x = -x
+			}
+			if i&4 != 0 {
+				y = -y
+			}
+			transformed[j] = Point{x, y}
+		}
+
+		if s := shape(transformed); best == "" || s < best {
+			best = s
+		}
+	}
+	return best
+}
+
+// shape draws points as rows of '.' and 'o' separated by '/'
+func shape(points []Point) string {
+	left, top, width, height := bounds(points)
+
+	grid := make([][]byte, height)
+	for y := range grid {
+		grid[y] = []byte(strings.Repeat(".", width))
+	}
+	for _, p := range points {
+		grid[p.Y-top][p.X-left] = 'o'
+	}
+
+	rows := make([]string, height)
+	for y, row := range grid {
+		rows[y] = string(row)
+	}
+	return strings.Join(rows, "/")
+}
+
+// Identify finds every object on the board and what it is
+func Identify(board [][]life.Cell) []Object {
+	height := len(board)
+	if height == 0 {
+		return nil
+	}
+	width := len(board[0])
+
+	var objects []Object
+	owners := make(map[int]int)
+
+	for _, points := range Components(board) {
+		left, top, w, h := bounds(points)
+
+		for k := range owners {
+			delete(owners, k)
+		}
+		for _, p := range points {
+			owners[board[util.Mod(p.Y, height)][util.Mod(p.X, width)].Player]++
+		}
+		owner := 0
+		for id, n := range owners {
+			// Ties go to the lowest id, so results don't change at random
+			if owner == 0 || n > owners[owner] || (n == owners[owner] && id < owner) {
+				owner = id
+			}
+		}
+
+		o := Object{
+			Player: owner,
+			X:      util.Mod(left, width),
+			Y:      util.Mod(top, height),
+			Width:  w,
+			Height: h,
+			Cells:  len(points),
+		}
+		// Most of the board's cells are in messes too big to be in the catalog
+		if len(points) <= maxCells && util.Max(w, h) <= maxSize {
+			o.Pattern = catalog[Canonical(points)]
+		}
+		objects = append(objects, o)
+	}
+	return objects
+}
+
+// Census counts identified objects by player and pattern name
+func Census(objects []Object) map[int]map[string]int {
+	census := make(map[int]map[string]int)
+	for _, o := range objects {
+		if o.Pattern == nil {
+			continue
+		}
+		if census[o.Player] == nil {
+			census[o.Player] = make(map[string]int)
+		}
+		census[o.Player][o.Pattern.Name]++
+	}
+	return census
+}
+
+// Count is how many of a pattern there are
+type Count struct {
+	Name string
+	N    int
+}
+
+type byCount []Count
+
+func (s byCount) Len() int {
+	return len(s)
+}
+func (s byCount) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+func (s byCount) Less(i, j int) bool {
+	if s[i].N != s[j].N {
+		return s[i].N > s[j].N
+	}
+	return s[i].Name < s[j].Name
+}
+
+// Sorted lists counts from most to least common
+func Sorted(counts map[string]int) []Count {
+	var sorted []Count
+	for name, n := range counts {
+		sorted = append(sorted, Count{name, n})
+	}
+	sort.Sort(byCount(sorted))
+	return sorted
+}
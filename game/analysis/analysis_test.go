@@ -0,0 +1,148 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/zhengkyl/gol/game/life"
+)
+
+func TestCatalog(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   Kind
+		period int
+	}{
+		{"block", StillLife, 1},
+		{"blinker", Oscillator, 2},
+		{"pulsar", Oscillator, 3},
+		{"pentadecathlon", Oscillator, 15},
+		{"glider", Spaceship, 4},
+		{"LWSS", Spaceship, 4},
+	}
+
+	found := make(map[string]*Pattern)
+	for _, p := range catalog {
+		found[p.Name] = p
+	}
+	for _, tt := range tests {
+		p := found[tt.name]
+		if p == nil {
+			t.Errorf("%v missing from catalog", tt.name)
+			continue
+		}
+		if p.Kind != tt.kind || p.Period != tt.period {
+			t.Errorf("%v is a %v with period %v, want %v with period %v", tt.name, p.Kind, p.Period, tt.kind, tt.period)
+		}
+	}
+}
+
+func place(board [][]life.Cell, player, left, top int, cells ...Point) {
+	for _, c := range cells {
+		y := (top + c.Y) % len(board)
+		x := (left + c.X) % len(board[0])
+		board[y][x].Player = player
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	board := life.NewBoard(30, 20)
+	// A glider pointing the other way
+	place(board, 1, 2, 2, Point{1, 0}, Point{0, 1}, Point{0, 2}, Point{1, 2}, Point{2, 2})
+	place(board, 2, 12, 2, Point{0, 0}, Point{1, 0}, Point{0, 1}, Point{1, 1})
+	// A vertical blinker crossing the top and bottom edges
+	place(board, 2, 20, 19, Point{0, 0}, Point{0, 1}, Point{0, 2})
+	// Too close to anything in the catalog
+	place(board, 1, 12, 10, Point{0, 0}, Point{2, 0}, Point{4, 0})
+
+	objects := Identify(board)
+	if len(objects) != 4 {
+		t.Fatalf("found %v objects, want 4", len(objects))
+	}
+
+	want := []struct {
+		name   string
+		player int
+		x, y   int
+	}{
+		{"blinker", 2, 20, 19},
+		{"glider", 1, 2, 2},
+		{"block", 2, 12, 2},
+		{"", 1, 12, 10},
+	}
+	for i, w := range want {
+		o := objects[i]
+		if o.Name() != w.name || o.Player != w.player || o.X != w.x || o.Y != w.y {
+			t.Errorf("object %v is %q of %v at (%v, %v), want %q of %v at (%v, %v)", i, o.Name(), o.Player, o.X, o.Y, w.name, w.player, w.x, w.y)
+		}
+	}
+
+	census := Census(objects)
+	if census[1]["glider"] != 1 || census[2]["block"] != 1 || census[2]["blinker"] != 1 || len(census[1]) != 1 {
+		t.Errorf("census is %v", census)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tests := []struct {
+		cells []Point
+		want  Stability
+	}{
+		{[]Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}}, Stability{0, 1, false}},
+		{[]Point{{0, 0}, {1, 0}, {2, 0}}, Stability{0, 2, false}},
+		{[]Point{{0, 0}, {1, 0}}, Stability{1, 1, true}},
+		// The glider comes back to where it started after crossing the board
+		{[]Point{{1, 0}, {2, 1}, {0, 2}, {1, 2}, {2, 2}}, Stability{0, 40, false}},
+	}
+
+	for _, tt := range tests {
+		board := life.NewBoard(10, 10)
+		place(board, 1, 4, 4, tt.cells...)
+
+		tracker := NewTracker()
+		var got Stability
+		for gen := 0; gen < 100; gen++ {
+			if s, ok := tracker.Add(board, gen); ok {
+				got = s
+				break
+			}
+			board = life.NextBoard(board)
+		}
+		if got != tt.want {
+			t.Errorf("%v settled as %+v, want %+v", tt.cells, got, tt.want)
+		}
+	}
+}
+
+func TestHashOwners(t *testing.T) {
+	a := life.NewBoard(4, 4)
+	b := life.NewBoard(4, 4)
+	a[1][1].Player = 1
+	b[1][1].Player = 257
+	if Hash(a) == Hash(b) {
+		t.Error("players 1 and 257 hash the same")
+	}
+	if Hash(life.NewBoard(2, 8)) == Hash(life.NewBoard(4, 4)) {
+		t.Error("2x8 and 4x4 boards hash the same")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	soup := Soup{Seed: 1, Size: 8, Density: 40}
+	results := Search(soup, 20, 32, 32, 2000, 5)
+	if len(results) == 0 || len(results) > 5 {
+		t.Fatalf("got %v results", len(results))
+	}
+	for i, r := range results {
+		if i > 0 && r.Lifespan() > results[i-1].Lifespan() {
+			t.Errorf("results aren't longest first: %v after %v", r.Lifespan(), results[i-1].Lifespan())
+		}
+		if r.Width != 32 || r.Height != 32 {
+			t.Errorf("seed %v ran on %vx%v", r.Soup.Seed, r.Width, r.Height)
+		}
+		// Replaying a seed gives the same result
+		soup.Seed = r.Soup.Seed
+		if again := RunSoup(soup, 32, 32, 2000); again.Stability != r.Stability {
+			t.Errorf("seed %v settled as %+v, then %+v", r.Soup.Seed, r.Stability, again.Stability)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package analysis
+
+import (
+	"strings"
+
+	"github.com/zhengkyl/gol/game/life"
+)
+
+// Kind is how a pattern behaves over time
+type Kind int
+
+const (
+	StillLife Kind = iota
+	Oscillator
+	Spaceship
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Oscillator:
+		return "oscillator"
+	case Spaceship:
+		return "spaceship"
+	}
+	return "still life"
+}
+
+// Pattern is a known object
+type Pattern struct {
+	Name string
+	Kind Kind
+	// Generations until the pattern repeats, which is 1 for still lifes
+	Period int
+}
+
+// Patterns are drawn as rows of '.' and 'o' separated by '/'. Only one phase
+// is needed, the rest are found by running them.
+var patterns = []struct {
+	name  string
+	cells string
+}{
+	{"block", "oo/oo"},
+	{"beehive", ".oo./o..o/.oo."},
+	{"loaf", ".oo./o..o/.o.o/..o."},
+	{"boat", "oo./o.o/.o."},
+	{"ship", "oo./o.o/.oo"},
+	{"tub", ".o./o.o/.o."},
+	{"pond", ".oo./o..o/o..o/.oo."},
+	{"blinker", "ooo"},
+	{"toad", ".ooo/ooo."},
+	{"beacon", "oo../oo../..oo/..oo"},
+	{"pulsar", "..ooo...ooo../............./o....o.o....o/o....o.o....o/o....o.o....o/..ooo...ooo../............./..ooo...ooo../o....o.o....o/o....o.o....o/o....o.o....o/............./..ooo...ooo.."},
+	{"pentadecathlon", "..o....o../oo.oooo.oo/..o....o.."},
+	{"glider", ".o./..o/ooo"},
+	{"LWSS", ".o..o/o..../o...o/oooo."},
+	{"MWSS", "...o../.o...o/o...../o....o/ooooo."},
+	{"HWSS", "...oo../.o....o/o....../o.....o/oooooo."},
+}
+
+// catalog maps the canonical shape of every phase of every pattern to it
+var catalog = make(map[string]*Pattern)
+
+// Objects bigger than every catalog phase can be skipped
+var maxCells, maxSize int
+
+// Longest period looked for when running patterns
+const maxPeriod = 30
+
+// Room around a pattern while running it, so it doesn't wrap into itself
+const padding = 10
+
+func init() {
+	for _, p := range patterns {
+		addPattern(p.name, p.cells)
+	}
+}
+
+// addPattern runs a pattern until it repeats, adding each phase to catalog
+func addPattern(name, cells string) {
+	rows := strings.Split(cells, "/")
+	board := life.NewBoard(len(rows[0])+2*padding, len(rows)+2*padding)
+	for y, row := range rows {
+		for x, c := range row {
+			if c == 'o' {
+				board[y+padding][x+padding].Player = 1
+			}
+		}
+	}
+
+	pattern := &Pattern{Name: name}
+	var phases [][]Point
+	// Compared without rotating or reflecting, since some patterns turn
+	// into mirror images of themselves partway through
+	start := ""
+
+	for gen := 0; gen <= maxPeriod; gen++ {
+		var points []Point
+		for y := range board {
+			for x := range board[y] {
+				if board[y][x].Player != life.DeadPlayer {
+					points = append(points, Point{x, y})
+				}
+			}
+		}
+
+		current := shape(points)
+		if gen == 0 {
+			start = current
+		} else if current == start {
+			pattern.Period = gen
+			left, top, _, _ := bounds(points)
+			startLeft, startTop, _, _ := bounds(phases[0])
+			switch {
+			case left != startLeft || top != startTop:
+				pattern.Kind = Spaceship
+			case gen > 1:
+				pattern.Kind = Oscillator
+			}
+			break
+		}
+		phases = append(phases, points)
+		board = life.NextBoard(board)
+	}
+	if pattern.Period == 0 {
+		panic("analysis: " + name + " doesn't repeat")
+	}
+
+	for _, points := range phases {
+		_, _, w, h := bounds(points)
+		if len(points) > maxCells {
+			maxCells = len(points)
+		}
+		if w > maxSize {
+			maxSize = w
+		}
+		if h > maxSize {
+			maxSize = h
+		}
+		catalog[Canonical(points)] = pattern
+	}
+}
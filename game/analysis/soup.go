@@ -0,0 +1,145 @@
+package analysis
+
+import (
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/zhengkyl/gol/game/life"
+)
+
+// Soup is a square of random cells, which is the same for the same seed
+type Soup struct {
+	Seed int64
+	Size int
+	// Percent of cells that start alive
+	Density int
+}
+
+// Fill replaces the soup's area of a board, centered on it, with cells owned
+// by player
+func (s Soup) Fill(board [][]life.Cell, player int) {
+	height := len(board)
+	width := len(board[0])
+	top, left := (height-s.Size)/2, (width-s.Size)/2
+
+	r := rand.New(rand.NewSource(s.Seed))
+	for y := top; y < top+s.Size; y++ {
+		for x := left; x < left+s.Size; x++ {
+			if y < 0 || y >= height || x < 0 || x >= width {
+				// Still drawn, so soups are the same on any board
+				r.Intn(100)
+				continue
+			}
+			board[y][x].Player = life.DeadPlayer
+			if r.Intn(100) < s.Density {
+				board[y][x].Player = player
+			}
+		}
+	}
+}
+
+// SoupResult is how a soup ended
+type SoupResult struct {
+	Soup Soup
+	// Size of the board it ran on, which it needs to run the same way again
+	Width, Height int
+	// Whether it settled within the generation limit
+	Settled   bool
+	Stability Stability
+	// Identified objects once it settled
+	Census map[string]int
+}
+
+// Lifespan is how many generations the soup changed for
+func (r SoupResult) Lifespan() int {
+	return r.Stability.Generation
+}
+
+// RunSoup runs a soup on an empty width x height board until it settles, or
+// for at most limit generations
+func RunSoup(s Soup, width, height, limit int) SoupResult {
+	board := life.NewBoard(width, height)
+	s.Fill(board, 1)
+
+	result := SoupResult{Soup: s, Width: width, Height: height}
+	tracker := NewTracker()
+	for gen := 0; gen <= limit; gen++ {
+		if stability, ok := tracker.Add(board, gen); ok {
+			result.Settled = true
+			result.Stability = stability
+			result.Census = Census(Identify(board))[1]
+			break
+		}
+		board = life.NextBoard(board)
+	}
+	return result
+}
+
+type byLifespan []SoupResult
+
+func (s byLifespan) Len() int {
+	return len(s)
+}
+func (s byLifespan) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+func (s byLifespan) Less(i, j int) bool {
+	if s[i].Lifespan() != s[j].Lifespan() {
+		return s[i].Lifespan() > s[j].Lifespan()
+	}
+	return s[i].Soup.Seed < s[j].Soup.Seed
+}
+
+// Searches are shared by every session on a server, so only one runs at a
+// time, on at most maxWorkers cores
+const maxWorkers = 4
+
+var searching = make(chan struct{}, 1)
+
+// Search runs count soups with seeds starting at s.Seed, and returns the keep
+// longest lived ones that settled. It waits for any other search to finish
+// first.
+func Search(s Soup, count, width, height, limit, keep int) []SoupResult {
+	searching <- struct{}{}
+	defer func() { <-searching }()
+
+	workers := runtime.NumCPU()
+	if workers > maxWorkers {
+		workers = maxWorkers
+	}
+
+	results := make([]SoupResult, count)
+	seeds := make(chan int)
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range seeds {
+				soup := s
+				soup.Seed += int64(i)
+				results[i] = RunSoup(soup, width, height, limit)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		seeds <- i
+	}
+	close(seeds)
+	wg.Wait()
+
+	settled := results[:0]
+	for _, r := range results {
+		if r.Settled {
+			settled = append(settled, r)
+		}
+	}
+	sort.Sort(byLifespan(settled))
+	if len(settled) > keep {
+		settled = settled[:keep]
+	}
+	return settled
+}
@@ -0,0 +1,89 @@
+package analysis
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/zhengkyl/gol/game/life"
+)
+
+// maxTracked is how many states a Tracker remembers before starting over, so
+// boards that never settle don't use up memory. Cycles longer than this are
+// never noticed.
+const maxTracked = 10000
+
+// Stability is how a board settled
+type Stability struct {
+	// First generation of the cycle the board ended up in
+	Generation int
+	// Generations per cycle, which is 1 for boards that stopped changing
+	Period int
+	// Whether every cell died
+	Extinct bool
+}
+
+func (s Stability) String() string {
+	if s.Extinct {
+		return fmt.Sprintf("died out at generation %d", s.Generation)
+	}
+	return fmt.Sprintf("stabilized at generation %d with period %d", s.Generation, s.Period)
+}
+
+// Tracker notices when a board repeats an earlier state
+type Tracker struct {
+	seen map[uint64]int
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{seen: make(map[uint64]int)}
+}
+
+// Add records the board at a generation. Once the board repeats a state, it
+// returns how it settled.
+func (t *Tracker) Add(board [][]life.Cell, generation int) (Stability, bool) {
+	h := Hash(board)
+	if first, ok := t.seen[h]; ok {
+		return Stability{
+			Generation: first,
+			Period:     generation - first,
+			Extinct:    Population(board) == 0,
+		}, true
+	}
+
+	if len(t.seen) >= maxTracked {
+		t.seen = make(map[uint64]int)
+	}
+	t.seen[h] = generation
+	return Stability{}, false
+}
+
+// Hash identifies a board state. Different states can collide, but it's
+// unlikely enough not to matter.
+func Hash(board [][]life.Cell) uint64 {
+	h := fnv.New64a()
+	// Otherwise boards of different shapes with the same cells collide
+	row := binary.LittleEndian.AppendUint64(nil, uint64(len(board[0])))
+	h.Write(row)
+	for _, cells := range board {
+		row = row[:0]
+		for _, c := range cells {
+			row = binary.LittleEndian.AppendUint64(row, uint64(c.Player))
+		}
+		h.Write(row)
+	}
+	return h.Sum64()
+}
+
+// Population counts the live cells on a board
+func Population(board [][]life.Cell) int {
+	n := 0
+	for _, row := range board {
+		for _, c := range row {
+			if c.Player != life.DeadPlayer {
+				n++
+			}
+		}
+	}
+	return n
+}
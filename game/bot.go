@@ -0,0 +1,438 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zhengkyl/gol/game/life"
+	"github.com/zhengkyl/gol/util"
+)
+
+// Difficulty is how quickly and cleverly bots play
+type Difficulty int
+
+const (
+	Easy Difficulty = iota
+	Normal
+	Hard
+)
+
+var Difficulties = []string{"easy", "normal", "hard"}
+
+func ParseDifficulty(s string) (Difficulty, error) {
+	for i, name := range Difficulties {
+		if s == name {
+			return Difficulty(i), nil
+		}
+	}
+	return Normal, fmt.Errorf("difficulty must be one of %v, got %q", strings.Join(Difficulties, ", "), s)
+}
+
+func (d Difficulty) String() string {
+	if d < 0 || int(d) >= len(Difficulties) {
+		return fmt.Sprintf("Difficulty(%d)", int(d))
+	}
+	return Difficulties[d]
+}
+
+// think is how long a bot waits between actions, like placing a cell
+func (d Difficulty) think() time.Duration {
+	switch d {
+	case Easy:
+		return 300 * time.Millisecond
+	case Hard:
+		return 40 * time.Millisecond
+	}
+	return 120 * time.Millisecond
+}
+
+// patience is how long a bot lets its cells play before building again
+func (d Difficulty) patience() time.Duration {
+	switch d {
+	case Easy:
+		return 40 * time.Second
+	case Hard:
+		return 15 * time.Second
+	}
+	return 25 * time.Second
+}
+
+// aims reports whether a bot bothers to point its spaceships at the leader
+func (d Difficulty) aims(r *rand.Rand) bool {
+	switch d {
+	case Easy:
+		return false
+	case Hard:
+		return true
+	}
+	return r.Intn(2) == 0
+}
+
+// Strategy is what a bot builds
+type Strategy int
+
+const (
+	// Gliders sends a few gliders off
+	Gliders Strategy = iota
+	// Walls lines up blocks that get in other players' way
+	Walls
+	// Guns builds a glider gun, or gliders if it's not allowed enough cells
+	Guns
+	numStrategies
+)
+
+func (s Strategy) String() string {
+	switch s {
+	case Walls:
+		return "wall"
+	case Guns:
+		return "gun"
+	}
+	return "glide"
+}
+
+// Designs move down and right, and are flipped to aim elsewhere
+var (
+	gliderCells = [][2]int{{1, 0}, {2, 1}, {0, 2}, {1, 2}, {2, 2}}
+	blockCells  = [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}}
+	// Gosper's glider gun
+	gunCells = [][2]int{
+		{24, 0},
+		{22, 1}, {24, 1},
+		{12, 2}, {13, 2}, {20, 2}, {21, 2}, {34, 2}, {35, 2},
+		{11, 3}, {15, 3}, {20, 3}, {21, 3}, {34, 3}, {35, 3},
+		{0, 4}, {1, 4}, {10, 4}, {16, 4}, {20, 4}, {21, 4},
+		{0, 5}, {1, 5}, {10, 5}, {14, 5}, {16, 5}, {17, 5}, {22, 5}, {24, 5},
+		{10, 6}, {16, 6}, {24, 6},
+		{11, 7}, {15, 7},
+		{12, 8}, {13, 8},
+	}
+)
+
+// How many random spots a bot looks at for room to build
+const botSearches = 20
+
+// bot plays in a lobby through the same methods as players
+type bot struct {
+	lobby      *Lobby
+	id         int
+	strategy   Strategy
+	difficulty Difficulty
+	r          *rand.Rand
+	// Paused cells still to be placed or removed, in order
+	todo []paint
+	// Where the bot's paused cells are
+	placed map[[2]int]bool
+	// When to stop playing and build again
+	rebuild time.Time
+	// The generation the bot started playing at
+	started int
+}
+
+type paint struct {
+	x, y  int
+	alive bool
+}
+
+func newBot(l *Lobby, id int, strategy Strategy, difficulty Difficulty) *bot {
+	return &bot{
+		lobby:      l,
+		id:         id,
+		strategy:   strategy,
+		difficulty: difficulty,
+		r:          rand.New(rand.NewSource(time.Now().UnixNano() + int64(id))),
+		placed:     make(map[[2]int]bool),
+	}
+}
+
+// run acts until the bot is removed from its lobby
+func (b *bot) run() {
+	ticker := time.NewTicker(b.difficulty.think())
+	defer ticker.Stop()
+
+	for range ticker.C {
+		paused, ok := b.lobby.paused(b.id)
+		if !ok {
+			return
+		}
+		b.act(paused)
+	}
+}
+
+// act does one thing, so bots play about as fast as people
+func (b *bot) act(paused bool) {
+	if !paused {
+		// Going back to editing clears the bot's cells, so it waits until
+		// they've done what they can. Cells are only counted each generation.
+		dead := b.lobby.Generation() > b.started && b.lobby.cells(b.id) == 0
+		if dead || time.Now().After(b.rebuild) {
+			b.lobby.TogglePause(b.id)
+			b.plan()
+		}
+		return
+	}
+
+	if len(b.todo) > 0 {
+		next := b.todo[0]
+		b.todo = b.todo[1:]
+		b.lobby.MoveCursor(b.id, next.x, next.y)
+		// Placing fails at the limit or on someone else's cell
+		if b.lobby.Paint(b.id, next.alive) {
+			b.placed[[2]int{next.x, next.y}] = true
+		} else {
+			delete(b.placed, [2]int{next.x, next.y})
+		}
+		return
+	}
+
+	if len(b.placed) == 0 {
+		b.plan()
+		return
+	}
+
+	b.lobby.TogglePause(b.id)
+	if paused, _ := b.lobby.paused(b.id); paused {
+		// Something is alive where the bot built, so it builds elsewhere
+		b.plan()
+		return
+	}
+	b.rebuild = time.Now().Add(b.difficulty.patience())
+	b.started = b.lobby.Generation()
+}
+
+// plan picks what to build next and where
+func (b *bot) plan() {
+	width, height := b.lobby.BoardSize()
+	budget := b.lobby.Settings().MaxPlacedCells
+
+	var design [][2]int
+	switch b.strategy {
+	case Walls:
+		design = b.wall(budget)
+	case Guns:
+		if budget >= len(gunCells) {
+			design = gunCells
+			break
+		}
+		fallthrough
+	default:
+		design = b.gliders(budget)
+	}
+	if len(design) == 0 {
+		return
+	}
+
+	designWidth, designHeight := size(design)
+	x, y := b.r.Intn(width), b.r.Intn(height)
+	for i := 0; i < botSearches; i++ {
+		if b.lobby.clear(x-2, y-2, designWidth+4, designHeight+4) {
+			break
+		}
+		x, y = b.r.Intn(width), b.r.Intn(height)
+	}
+
+	// Flipped to head towards the leader, or anywhere
+	flipX, flipY := b.r.Intn(2) == 0, b.r.Intn(2) == 0
+	if b.difficulty.aims(b.r) {
+		if tx, ty, ok := b.lobby.target(b.id); ok {
+			flipX = towards(x+designWidth/2, tx, width) < 0
+			flipY = towards(y+designHeight/2, ty, height) < 0
+		}
+	}
+
+	var cells [][2]int
+	wanted := make(map[[2]int]bool)
+	for _, c := range design {
+		cx, cy := c[0], c[1]
+		if flipX {
+			cx = designWidth - 1 - cx
+		}
+		if flipY {
+			cy = designHeight - 1 - cy
+		}
+		pos := [2]int{util.Mod(x+cx, width), util.Mod(y+cy, height)}
+		cells = append(cells, pos)
+		wanted[pos] = true
+	}
+
+	// Old cells are cleared first, so there's budget for the new ones
+	b.todo = nil
+	for pos := range b.placed {
+		if !wanted[pos] {
+			b.todo = append(b.todo, paint{pos[0], pos[1], false})
+		}
+	}
+	for _, pos := range cells {
+		if !b.placed[pos] {
+			b.todo = append(b.todo, paint{pos[0], pos[1], true})
+		}
+	}
+}
+
+// gliders lines up as many gliders as the budget allows, up to 4
+func (b *bot) gliders(budget int) [][2]int {
+	var cells [][2]int
+	for i := 0; i < 4 && len(cells)+len(gliderCells) <= budget; i++ {
+		for _, c := range gliderCells {
+			// Spaced so they don't touch
+			cells = append(cells, [2]int{c[0] + i*5, c[1]})
+		}
+	}
+	return cells
+}
+
+// wall lines up blocks across or down the board
+func (b *bot) wall(budget int) [][2]int {
+	vertical := b.r.Intn(2) == 0
+	var cells [][2]int
+	for i := 0; i < 8 && len(cells)+len(blockCells) <= budget; i++ {
+		for _, c := range blockCells {
+			// Blocks 3 apart stay still without merging
+			if vertical {
+				cells = append(cells, [2]int{c[0], c[1] + i*5})
+			} else {
+				cells = append(cells, [2]int{c[0] + i*5, c[1]})
+			}
+		}
+	}
+	return cells
+}
+
+// size returns the width and height of a design
+func size(cells [][2]int) (int, int) {
+	w, h := 0, 0
+	for _, c := range cells {
+		w = util.Max(w, c[0]+1)
+		h = util.Max(h, c[1]+1)
+	}
+	return w, h
+}
+
+// towards returns which way is shortest from a to b on a wrapping axis
+func towards(a, b, size int) int {
+	d := util.Mod(b-a, size)
+	if d == 0 {
+		return 0
+	}
+	if d <= size/2 {
+		return 1
+	}
+	return -1
+}
+
+// cells counts a player's live cells as of the last generation
+func (l *Lobby) cells(id int) int {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+
+	if p, ok := l.players[id]; ok {
+		return p.Cells
+	}
+	return 0
+}
+
+// clear reports whether an area has no live or paused cells
+func (l *Lobby) clear(left, top, width, height int) bool {
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+
+	boardWidth, boardHeight := len(l.board[0]), len(l.board)
+	for y := top; y < top+height; y++ {
+		for x := left; x < left+width; x++ {
+			cell := l.board[util.Mod(y, boardHeight)][util.Mod(x, boardWidth)]
+			if cell.Player != life.DeadPlayer || cell.PausedPlayer != life.DeadPlayer {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// target finds the biggest group of cells of whoever is winning, other than
+// the player with id
+func (l *Lobby) target(id int) (int, int, bool) {
+	l.playersMutex.RLock()
+	leader := 0
+	most := 0
+	for _, p := range l.players {
+		if p.Id != id && p.Cells > most {
+			leader = p.Id
+			most = p.Cells
+		}
+	}
+	l.playersMutex.RUnlock()
+
+	if leader == 0 {
+		return 0, 0, false
+	}
+	return l.LargestCluster(leader)
+}
+
+// Bots lists the ids of every bot in the lobby
+func (l *Lobby) Bots() []int {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+
+	var ids []int
+	for _, p := range l.players {
+		if p.Bot {
+			ids = append(ids, p.Id)
+		}
+	}
+	return ids
+}
+
+// paused is whether a player is editing, and false for ok if they've left
+func (l *Lobby) paused(playerId int) (paused, ok bool) {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+
+	p, ok := l.players[playerId]
+	if !ok {
+		return false, false
+	}
+	return p.Paused, true
+}
+
+// Humans counts the players who aren't bots, including disconnected ones
+func (l *Lobby) Humans() int {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+	return l.humans()
+}
+
+// humans is Humans for callers holding playersMutex
+func (l *Lobby) humans() int {
+	n := 0
+	for _, p := range l.players {
+		if !p.Bot {
+			n++
+		}
+	}
+	return n
+}
+
+// kickBot removes the newest bot to make room, and reports whether there was
+// one
+func (l *Lobby) kickBot() bool {
+	bots := l.Bots()
+	if len(bots) == 0 {
+		return false
+	}
+	sort.Ints(bots)
+	return l.Leave(bots[len(bots)-1]) != nil
+}
+
+// addBot joins a bot to the lobby and starts it playing
+func (l *Lobby) addBot(id int, strategy Strategy, difficulty Difficulty) error {
+	name := fmt.Sprintf("%sbot", strategy)
+	if _, err := l.join(id, name, nil, true); err != nil {
+		return err
+	}
+
+	go newBot(l, id, strategy, difficulty).run()
+	return nil
+}
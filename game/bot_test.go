@@ -0,0 +1,109 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/zhengkyl/gol/game/analysis"
+	"github.com/zhengkyl/gol/game/life"
+)
+
+func TestGunShoots(t *testing.T) {
+	board := life.NewBoard(80, 60)
+	for _, c := range gunCells {
+		board[c[1]+2][c[0]+2].Player = 1
+	}
+	for i := 0; i < 70; i++ {
+		board = life.NextBoard(board)
+	}
+
+	gliders := 0
+	for _, o := range analysis.Identify(board) {
+		if o.Pattern != nil && o.Pattern.Name == "glider" {
+			gliders++
+		}
+	}
+	if gliders < 2 {
+		t.Errorf("got %v gliders after 70 generations, want at least 2", gliders)
+	}
+}
+
+func TestToggleBots(t *testing.T) {
+	gm := NewManager(nil, DefaultSettings)
+	lobbyId, err := gm.CreateLobby()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gm.Connect(startedClient(), "host", "host")
+	msg, ok := gm.JoinLobby(lobbyId, id).(JoinSuccessMsg)
+	if !ok {
+		t.Fatal("could not join")
+	}
+	lobby := msg.Lobby
+
+	gm.ToggleBots(id)
+	if n := len(lobby.Bots()); n != DefaultSettings.MaxBots {
+		t.Errorf("got %v bots, want %v", n, DefaultSettings.MaxBots)
+	}
+	if host := lobby.Host(); host != id {
+		t.Errorf("bot %v is host", host)
+	}
+
+	gm.ToggleBots(id)
+	if n := len(lobby.Bots()); n != 0 {
+		t.Errorf("got %v bots after removing them", n)
+	}
+
+	gm.ToggleBots(id)
+	gm.LeaveLobby(id)
+	if infos := gm.LobbyInfos(); len(infos) != 0 {
+		t.Errorf("bots kept an empty lobby open: %+v", infos)
+	}
+}
+
+func TestBotsDontCountForRounds(t *testing.T) {
+	gm := NewManager(nil, DefaultSettings)
+	lobbyId, err := gm.CreateLobby()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gm.Connect(startedClient(), "host", "host")
+	lobby := gm.JoinLobby(lobbyId, id).(JoinSuccessMsg).Lobby
+	gm.ToggleBots(id)
+	defer gm.ToggleBots(id)
+
+	lobby.boardMutex.Lock()
+	for _, c := range blockCells {
+		lobby.board[c[1]+2][c[0]+2].Player = id
+	}
+	lobby.generation = roundLength - 1
+	lobby.boardMutex.Unlock()
+	lobby.UpdateBoard()
+
+	if won := lobby.GetPlayer(id).RoundsWon; won != 0 {
+		t.Errorf("won %v rounds against only bots", won)
+	}
+}
+
+func TestBotOnlyRecordsPlacedCells(t *testing.T) {
+	settings := DefaultSettings
+	settings.MaxPlacedCells = 2
+	l := newLobby("limit", settings)
+	if _, err := l.join(1, "bot", nil, true); err != nil {
+		t.Fatal(err)
+	}
+
+	b := newBot(l, 1, Gliders, Normal)
+	b.todo = []paint{{0, 0, true}, {1, 0, true}, {2, 0, true}}
+	for range b.todo {
+		b.act(true)
+	}
+	if len(b.placed) != 2 {
+		t.Errorf("bot thinks it placed %v cells, limit is 2", len(b.placed))
+	}
+}
+
+func TestDifficultyString(t *testing.T) {
+	if s := Difficulty(7).String(); s != "Difficulty(7)" {
+		t.Errorf("got %q", s)
+	}
+}
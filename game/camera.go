@@ -0,0 +1,91 @@
+package game
+
+import (
+	"sort"
+
+	"github.com/zhengkyl/gol/util"
+)
+
+// NextPlayer returns the id and cursor of the player after prev, in scoreboard
+// color order, skipping the player with id self. It's false if self is alone.
+func (l *Lobby) NextPlayer(self, prev int) (int, int, int, bool) {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+
+	var others []*PlayerState
+	for _, p := range l.players {
+		if p.Id != self {
+			others = append(others, p)
+		}
+	}
+	if len(others) == 0 {
+		return 0, 0, 0, false
+	}
+	sort.Sort(byColor(others))
+
+	next := others[0]
+	for i, p := range others {
+		if p.Id == prev {
+			next = others[(i+1)%len(others)]
+			break
+		}
+	}
+	return next.Id, next.PosX, next.PosY, true
+}
+
+// LargestCluster returns the middle of the biggest group of touching cells
+// owned by a player. It's false if the player has no cells.
+func (l *Lobby) LargestCluster(id int) (int, int, bool) {
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+
+	height := len(l.board)
+	width := len(l.board[0])
+
+	seen := make([][]bool, height)
+	for y := range seen {
+		seen[y] = make([]bool, width)
+	}
+
+	best, bestX, bestY := 0, 0, 0
+
+	for y := range l.board {
+		for x := range l.board[y] {
+			if seen[y][x] || l.board[y][x].Player != id {
+				continue
+			}
+
+			// Positions are kept unwrapped, so a cluster crossing the edge
+			// of the torus still averages to its middle
+			seen[y][x] = true
+			queue := [][2]int{{x, y}}
+			sumX, sumY := 0, 0
+
+			for i := 0; i < len(queue); i++ {
+				cx, cy := queue[i][0], queue[i][1]
+				sumX += cx
+				sumY += cy
+
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						nx, ny := cx+dx, cy+dy
+						bx, by := util.Mod(nx, width), util.Mod(ny, height)
+						if seen[by][bx] || l.board[by][bx].Player != id {
+							continue
+						}
+						seen[by][bx] = true
+						queue = append(queue, [2]int{nx, ny})
+					}
+				}
+			}
+
+			if len(queue) > best {
+				best = len(queue)
+				bestX = util.Mod(sumX/len(queue), width)
+				bestY = util.Mod(sumY/len(queue), height)
+			}
+		}
+	}
+
+	return bestX, bestY, best > 0
+}
@@ -0,0 +1,24 @@
+package game
+
+import "testing"
+
+func TestLargestClusterWraps(t *testing.T) {
+	settings := DefaultSettings
+	settings.Width, settings.Height = 10, 10
+	l := newLobby("test", settings)
+
+	l.board[0][5].Player = 1
+	// Crosses the left and right edges
+	l.board[5][9].Player = 1
+	l.board[5][0].Player = 1
+	l.board[5][1].Player = 1
+
+	x, y, ok := l.LargestCluster(1)
+	if !ok || x != 0 || y != 5 {
+		t.Errorf("got (%v, %v, %v), want (0, 5, true)", x, y, ok)
+	}
+
+	if _, _, ok := l.LargestCluster(2); ok {
+		t.Error("found a cluster for a player without cells")
+	}
+}
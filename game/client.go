@@ -0,0 +1,133 @@
+package game
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Frame rate limits for a single session. Slow connections are drawn less
+// often, down to minDrawRate.
+const (
+	minDrawRate = 2
+	// How many times a frame's write latency to wait between frames
+	latencyFactor = 2
+)
+
+// Client delivers board updates to one session. Lobbies notify clients
+// without blocking, and updates that arrive while one is still being drawn
+// are merged, so a slow connection only slows itself down.
+type Client struct {
+	program *tea.Program
+	// Holds at most one pending update
+	updates chan struct{}
+	done    chan struct{}
+	once    sync.Once
+
+	mu sync.Mutex
+	// Moving average of how long writes to the session take
+	latency time.Duration
+}
+
+func NewClient() *Client {
+	return &Client{
+		updates: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// Writer wraps the session's output to measure how fast the client keeps up
+func (c *Client) Writer(w io.Writer) io.Writer {
+	return &latencyWriter{w, c}
+}
+
+// Start begins sending updates to p
+func (c *Client) Start(p *tea.Program) {
+	c.program = p
+	go c.run()
+}
+
+// Program is nil until the client is started
+func (c *Client) Program() *tea.Program {
+	return c.program
+}
+
+// Notify queues an update unless one is already waiting
+func (c *Client) Notify() {
+	select {
+	case c.updates <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops sending updates
+func (c *Client) Close() {
+	c.once.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *Client) observe(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.latency += (d - c.latency) / 8
+}
+
+// Interval is the shortest time between frames for this client
+func (c *Client) Interval() time.Duration {
+	c.mu.Lock()
+	latency := c.latency
+	c.mu.Unlock()
+
+	interval := latency * latencyFactor
+	if interval < time.Second/drawRate {
+		interval = time.Second / drawRate
+	}
+	if interval > time.Second/minDrawRate {
+		interval = time.Second / minDrawRate
+	}
+	return interval
+}
+
+func (c *Client) run() {
+	timer := time.NewTimer(0)
+	<-timer.C
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-c.updates:
+		}
+
+		start := time.Now()
+		// Blocks until the program takes it, which is why this isn't
+		// done on the lobby's goroutine
+		c.program.Send(UpdateBoardMsg{})
+
+		wait := c.Interval() - time.Since(start)
+		if wait <= 0 {
+			continue
+		}
+		timer.Reset(wait)
+		select {
+		case <-c.done:
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+type latencyWriter struct {
+	w io.Writer
+	c *Client
+}
+
+func (lw *latencyWriter) Write(b []byte) (int, error) {
+	start := time.Now()
+	n, err := lw.w.Write(b)
+	lw.c.observe(time.Since(start))
+	return n, err
+}
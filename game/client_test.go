@@ -0,0 +1,49 @@
+package game
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+type slowWriter struct {
+	delay time.Duration
+}
+
+func (w slowWriter) Write(b []byte) (int, error) {
+	time.Sleep(w.delay)
+	return len(b), nil
+}
+
+func TestClientIntervalAdapts(t *testing.T) {
+	c := NewClient()
+	if got := c.Interval(); got != time.Second/drawRate {
+		t.Errorf("got interval %v before any writes, want %v", got, time.Second/drawRate)
+	}
+
+	w := c.Writer(slowWriter{40 * time.Millisecond})
+	for i := 0; i < 10; i++ {
+		io.WriteString(w, "frame")
+	}
+	if got := c.Interval(); got <= time.Second/drawRate || got > time.Second/minDrawRate {
+		t.Errorf("got interval %v for a slow client, want between %v and %v", got, time.Second/drawRate, time.Second/minDrawRate)
+	}
+}
+
+func TestClientNotifyDoesNotBlock(t *testing.T) {
+	// Not started, so nothing ever takes the updates
+	c := NewClient()
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			c.Notify()
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Notify blocked")
+	}
+}
@@ -1,5 +1,10 @@
 package game
 
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
 type playerColor struct {
 	Cursor string
 	Cell   string
@@ -51,3 +56,112 @@ var ColorTable = [11]playerColor{
 		"#eeeeee",
 	},
 }
+
+// Palette is a set of player colors. Index 0 is for dead cells.
+type Palette struct {
+	Name   string
+	Colors [len(ColorTable)]playerColor
+}
+
+var Palettes = []Palette{
+	{Name: "default", Colors: ColorTable},
+	{
+		// The first 7 are Okabe-Ito, which stay apart with common color
+		// blindness. Later players are easier to confuse, so glyphs help
+		// with full lobbies. Cursors are a shade off their cell color, so
+		// they show up over their own cells.
+		Name: "colorblind",
+		Colors: [len(ColorTable)]playerColor{
+			{"#080808", "0"},
+			{"#7e5700", "#e69f00"},
+			{"#2f6380", "#56b4e9"},
+			{"#00563f", "#009e73"},
+			{"#847d24", "#f0e442"},
+			{"#003e61", "#0072b2"},
+			{"#753300", "#d55e00"},
+			{"#70425b", "#cc79a7"},
+			{"#8c8c8c", "#ffffff"},
+			{"#797041", "#ddcc77"},
+			{"#c99bb2", "#882255"},
+		},
+	},
+	{
+		Name: "high contrast",
+		Colors: [len(ColorTable)]playerColor{
+			{"#080808", "0"},
+			{"#ff8c8c", "#ff0000"},
+			{"#008c00", "#00ff00"},
+			{"#34348c", "#5f5fff"},
+			{"#8c8c00", "#ffff00"},
+			{"#ff8cff", "#ff00ff"},
+			{"#008c8c", "#00ffff"},
+			{"#8c8c8c", "#ffffff"},
+			{"#8c4a00", "#ff8700"},
+			{"#604a8c", "#af87ff"},
+			{"#4a8c4a", "#87ff87"},
+		},
+	},
+}
+
+// PaletteIndex finds a palette by name, falling back to the default
+func PaletteIndex(name string) int {
+	for i, p := range Palettes {
+		if p.Name == name {
+			return i
+		}
+	}
+	return 0
+}
+
+// NeutralColor is used for cells that don't belong to anyone
+const NeutralColor = len(ColorTable)
+
+var neutralColor = playerColor{"#6c6c6c", "#6c6c6c"}
+
+// Glyphs tell players apart without color
+var Glyphs = [len(ColorTable)]string{"  ", "##", "@@", "%%", "&&", "$$", "**", "++", "==", "OO", "XX"}
+
+const neutralGlyph = ".."
+
+func glyph(color int) string {
+	if color == NeutralColor {
+		return neutralGlyph
+	}
+	return Glyphs[color]
+}
+
+// Monochrome reports whether r can't show colors
+func Monochrome(r *lipgloss.Renderer) bool {
+	return r.ColorProfile() == termenv.Ascii
+}
+
+// Theme is how a single session draws players
+type Theme struct {
+	Renderer *lipgloss.Renderer
+	// Index into Palettes
+	Palette int
+	// Draw player glyphs on cells, always on for monochrome terminals
+	Glyphs bool
+	// Escape sequences between looks, see render.go
+	sequences map[transition]string
+}
+
+func (t *Theme) color(color int) playerColor {
+	if color == NeutralColor {
+		return neutralColor
+	}
+	return Palettes[t.Palette].Colors[color]
+}
+
+func (t *Theme) ShowGlyphs() bool {
+	return t.Glyphs || Monochrome(t.Renderer)
+}
+
+// Avatar is a 2 column swatch identifying a player
+func (t *Theme) Avatar(color int) string {
+	style := t.Renderer.NewStyle().Background(lipgloss.Color(t.color(color).Cell))
+	if t.ShowGlyphs() {
+		return style.Foreground(lipgloss.Color(t.color(0).Cursor)).Render(Glyphs[color])
+	}
+	return style.Render("  ")
+}
@@ -0,0 +1,13 @@
+package game
+
+import "testing"
+
+func TestCursorsStandOut(t *testing.T) {
+	for _, p := range Palettes {
+		for i, c := range p.Colors[1:] {
+			if c.Cursor == c.Cell {
+				t.Errorf("%v palette: player %v's cursor can't be seen on their cells", p.Name, i+1)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zhengkyl/gol/util"
+)
+
+// HistoryLength is how many generations of population are remembered, enough
+// to fill a wide terminal
+const HistoryLength = 240
+
+// HistoryRows is how many players the population panel shows
+const HistoryRows = 5
+
+var sparks = []rune(" ▁▂▃▄▅▆▇█")
+
+// Record appends a population to a history, dropping the oldest past
+// HistoryLength
+func Record(history []int, population int) []int {
+	history = append(history, population)
+	if len(history) > HistoryLength {
+		history = history[len(history)-HistoryLength:]
+	}
+	return history
+}
+
+// Sparkline draws the last width values as bars up to max. Shorter histories
+// are right aligned, so the newest value is always at the end.
+func Sparkline(values []int, max, width int) string {
+	if width < 1 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(strings.Repeat(" ", width-len(values)))
+	for _, v := range values {
+		level := 0
+		if v > 0 && max > 0 {
+			// Round up, so any population at all shows
+			level = (v*(len(sparks)-1) + max - 1) / max
+			if level >= len(sparks) {
+				level = len(sparks) - 1
+			}
+		}
+		sb.WriteRune(sparks[level])
+	}
+	return sb.String()
+}
+
+// HistoryMax is the largest value in the last width values of histories, so
+// sparklines drawn together share a scale
+func HistoryMax(width int, histories ...[]int) int {
+	max := 0
+	for _, h := range histories {
+		if len(h) > width {
+			h = h[len(h)-width:]
+		}
+		for _, v := range h {
+			if v > max {
+				max = v
+			}
+		}
+	}
+	return max
+}
+
+// ViewHistory renders the population over time of the HistoryRows players
+// with the most cells, one sparkline each in their color
+func (l *Lobby) ViewHistory(t *Theme, width int) string {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+
+	var ps []*PlayerState
+	for _, p := range l.players {
+		ps = append(ps, p)
+	}
+	sort.Sort(sort.Reverse(byCells(ps)))
+	if len(ps) > HistoryRows {
+		ps = ps[:HistoryRows]
+	}
+
+	// Avatar, name and count around the sparkline
+	sparkWidth := util.Max(0, width-2-1-maxScoreboardName-1-1-5)
+	histories := make([][]int, len(ps))
+	for i, p := range ps {
+		histories[i] = p.History
+	}
+	max := HistoryMax(sparkWidth, histories...)
+
+	rows := make([]string, HistoryRows)
+	for i, p := range ps {
+		sb := strings.Builder{}
+		w := rowWriter{t: t, sb: &sb}
+		w.write(look{fg: lipgloss.Color(t.color(p.Color).Cell)}, Sparkline(p.History, max, sparkWidth))
+		w.end()
+
+		rows[i] = fmt.Sprintf("%s %-*s %s %5d", t.Avatar(p.Color), maxScoreboardName, truncateName(p.Name), sb.String(), p.Cells)
+	}
+	return strings.Join(rows, "\n")
+}
@@ -0,0 +1,32 @@
+package game
+
+import "testing"
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []int
+		max    int
+		width  int
+		want   string
+	}{
+		{[]int{0, 1, 4, 8}, 8, 4, " ▁▄█"},
+		{[]int{8}, 8, 3, "  █"},
+		{[]int{1, 2, 3}, 3, 2, "▆█"},
+		{[]int{0, 0}, 0, 2, "  "},
+	}
+	for _, tt := range tests {
+		if got := Sparkline(tt.values, tt.max, tt.width); got != tt.want {
+			t.Errorf("Sparkline(%v, %v, %v) = %q, want %q", tt.values, tt.max, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	var history []int
+	for i := 0; i < HistoryLength+10; i++ {
+		history = Record(history, i)
+	}
+	if len(history) != HistoryLength || history[0] != 10 {
+		t.Errorf("kept %v values starting at %v", len(history), history[0])
+	}
+}
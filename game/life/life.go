@@ -2,6 +2,9 @@ package life
 
 const DeadPlayer = 0
 
+// NeutralPlayer owns live cells that don't belong to anyone
+const NeutralPlayer = -1
+
 type Cell struct {
 	Player       int
 	PausedPlayer int
@@ -31,3 +31,58 @@ func BenchmarkNextBoard10(b *testing.B) {
 		board = NextBoard(board)
 	}
 }
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"B3/S23", "B3/S23"},
+		{"b36/s23", "B36/S23"},
+		{"S23/B3", "B3/S23"},
+		{"23/36", "B36/S23"},
+		{"B2/S", "B2/S"},
+	}
+	for _, tt := range tests {
+		r, err := ParseRule(tt.in)
+		if err != nil {
+			t.Errorf("ParseRule(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if r.String() != tt.want {
+			t.Errorf("ParseRule(%q) = %v, want %v", tt.in, r, tt.want)
+		}
+	}
+
+	for _, in := range []string{"B3", "B9/S23", "life"} {
+		if _, err := ParseRule(in); err == nil {
+			t.Errorf("ParseRule(%q) should fail", in)
+		}
+	}
+}
+
+func TestStepTopology(t *testing.T) {
+	// A blinker lying across the left and right edges
+	newBlinker := func() [][]Cell {
+		board := NewBoard(5, 5)
+		board[2][4].Player = 1
+		board[2][0].Player = 1
+		board[2][1].Player = 1
+		return board
+	}
+
+	torus := Step(newBlinker(), Conway, Torus)
+	if torus[1][0].Player != 1 || torus[2][0].Player != 1 || torus[3][0].Player != 1 || torus[2][1].Player != 0 {
+		t.Error("blinker didn't turn on a torus")
+	}
+
+	// Without wrapping, only 2 of its cells are left side by side
+	plane := Step(newBlinker(), Conway, Plane)
+	for y := range plane {
+		for x := range plane[y] {
+			if plane[y][x].Player != 0 {
+				t.Errorf("cell (%v, %v) alive on a plane", x, y)
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package life
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rule is which numbers of live neighbors make a cell born or survive
+type Rule struct {
+	Birth   [9]bool
+	Survive [9]bool
+}
+
+// Conway is the usual rule, B3/S23
+var Conway = Rule{
+	Birth:   [9]bool{3: true},
+	Survive: [9]bool{2: true, 3: true},
+}
+
+// ParseRule reads a rule like B3/S23, or the older S/B form like 23/3
+func ParseRule(s string) (Rule, error) {
+	var r Rule
+	parts := strings.Split(strings.ToUpper(strings.TrimSpace(s)), "/")
+	if len(parts) != 2 {
+		return r, fmt.Errorf("rule %q isn't like B3/S23", s)
+	}
+
+	birth, survive := parts[0], parts[1]
+	switch {
+	case strings.HasPrefix(birth, "B") && strings.HasPrefix(survive, "S"):
+		birth, survive = birth[1:], survive[1:]
+	case strings.HasPrefix(birth, "S") && strings.HasPrefix(survive, "B"):
+		birth, survive = survive[1:], birth[1:]
+	default:
+		// S/B without letters
+		birth, survive = survive, birth
+	}
+
+	read := func(counts string, into *[9]bool) error {
+		for _, c := range counts {
+			if c < '0' || c > '8' {
+				return fmt.Errorf("rule %q has invalid neighbor count %q", s, c)
+			}
+			into[c-'0'] = true
+		}
+		return nil
+	}
+	if err := read(birth, &r.Birth); err != nil {
+		return r, err
+	}
+	if err := read(survive, &r.Survive); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+func (r Rule) String() string {
+	sb := strings.Builder{}
+	sb.WriteString("B")
+	for n, ok := range r.Birth {
+		if ok {
+			sb.WriteByte(byte('0' + n))
+		}
+	}
+	sb.WriteString("/S")
+	for n, ok := range r.Survive {
+		if ok {
+			sb.WriteByte(byte('0' + n))
+		}
+	}
+	return sb.String()
+}
+
+// Topology is what's past the edges of a board
+type Topology int
+
+const (
+	// Torus boards wrap around, like lobbies
+	Torus Topology = iota
+	// Plane boards are surrounded by dead cells
+	Plane
+)
+
+func ParseTopology(s string) (Topology, error) {
+	switch s {
+	case "torus":
+		return Torus, nil
+	case "plane":
+		return Plane, nil
+	}
+	return Torus, fmt.Errorf("topology must be torus or plane, got %q", s)
+}
+
+func (t Topology) String() string {
+	if t == Plane {
+		return "plane"
+	}
+	return "torus"
+}
+
+// Step runs one generation of any rule and topology. New cells belong to
+// whoever owns most of their neighbors, or the lowest id on a tie. Unlike
+// NextBoard, no one owner needs 2 of the neighbors, and surviving cells keep
+// their owner.
+func Step(board [][]Cell, rule Rule, topology Topology) [][]Cell {
+	boardWidth := len(board[0])
+	boardHeight := len(board)
+
+	newBoard := NewBoard(boardWidth, boardHeight)
+
+	for y := range board {
+		for x := range board[y] {
+			newBoard[y][x].PausedPlayer = board[y][x].PausedPlayer
+
+			// At most 8 neighbors, so a slice is quicker than a map
+			var owners [8]int
+			var counts [8]int
+			numOwners := 0
+			numNeighbors := 0
+
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if dx == 0 && dy == 0 {
+						continue
+					}
+
+					ny, nx := y+dy, x+dx
+					if topology == Plane && (ny < 0 || ny >= boardHeight || nx < 0 || nx >= boardWidth) {
+						continue
+					}
+					ny = (ny + boardHeight) % boardHeight
+					nx = (nx + boardWidth) % boardWidth
+
+					player := board[ny][nx].Player
+					if player == DeadPlayer {
+						continue
+					}
+					numNeighbors++
+
+					i := 0
+					for i < numOwners && owners[i] != player {
+						i++
+					}
+					if i == numOwners {
+						owners[i] = player
+						numOwners++
+					}
+					counts[i]++
+				}
+			}
+
+			alive := board[y][x].Player != DeadPlayer
+			if alive && rule.Survive[numNeighbors] {
+				newBoard[y][x].Player = board[y][x].Player
+			} else if !alive && rule.Birth[numNeighbors] && numOwners > 0 {
+				most := 0
+				for i := 1; i < numOwners; i++ {
+					if counts[i] > counts[most] || (counts[i] == counts[most] && owners[i] < owners[most]) {
+						most = i
+					}
+				}
+				newBoard[y][x].Player = owners[most]
+			}
+		}
+	}
+	return newBoard
+}
@@ -8,49 +8,133 @@ import (
 	"sync"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/zhengkyl/gol/game/life"
+	"github.com/zhengkyl/gol/util"
 )
 
 type PlayerState struct {
-	Program *tea.Program
-	Id      int
-	PosX    int
-	PosY    int
-	VelX    int
-	VelY    int
-	Paused  bool
-	Color   int
-	Placed  int
-	Cells   int
+	Client *Client
+	Id     int
+	Name   string
+	PosX   int
+	PosY   int
+	VelX   int
+	VelY   int
+	Paused bool
+	Color  int
+	Placed int
+	Cells  int
+	// Cells at each of the last HistoryLength generations, oldest first
+	History []int
+	Emote   string
+	// Disconnected players keep their cells until they reconnect or time out
+	Disconnected bool
+	// Bots are played by the server and never host
+	Bot bool
+	// When the current emote stops being shown
+	emoteExpires time.Time
+	lastPing     time.Time
+	// Stats for this session in the lobby
+	Joined         time.Time
+	PeakCells      int
+	PatternsPlaced int
+	RoundsWon      int
 }
 
 type GameState int
 
 type Lobby struct {
 	players      map[int]*PlayerState
-	playerColors [11]bool
+	playerColors [len(ColorTable)]bool
 	playersMutex sync.RWMutex
 	playerCount  int
 	board        [][]life.Cell
 	boardMutex   sync.RWMutex
 	ticker       *time.Ticker
-	name         string
-	id           int
+	// Closed to stop the lobby running once it's deleted
+	done       chan struct{}
+	name       string
+	id         int
+	generation int
+	settings   Settings
+	// Persistent lobbies are saved to disk and kept when empty
+	persistent bool
+	// When someone last left, guarded by playersMutex
+	lastPlayed time.Time
+	// Guarded by playersMutex
+	pings []ping
+	// Index into Speeds, guarded by boardMutex
+	speed int
+	// Whether generations are stopped, guarded by boardMutex
+	frozen bool
+	// Generations owed, in 1/drawRate parts
+	progress int
+	// Held while deciding whether to run generations and running them, so
+	// steps and the ticker take turns
+	updateMutex sync.Mutex
+	// Bumped whenever the board changes, guarded by boardMutex
+	version     int
+	renders     map[renderKey]*boardRender
+	analyzed    *objectsCache
+	renderMutex sync.Mutex
+}
+
+type ping struct {
+	posX    int
+	posY    int
+	color   int
+	expires time.Time
+}
+
+// Settings are the rules every lobby in a Manager is created with
+type Settings struct {
+	Width          int
+	Height         int
+	MaxPlayers     int
+	MaxPlacedCells int
+	MaxLobbies     int
+	// Persistent worlds, which don't count as lobbies
+	MaxWorlds     int
+	BotDifficulty Difficulty
+	// Most bots in a lobby, or 0 for none
+	MaxBots int
+}
+
+// MaxPlayers is limited by the number of player colors
+const MaxPlayers = len(ColorTable) - 1
+
+var DefaultSettings = Settings{
+	Width:          160,
+	Height:         90,
+	MaxPlayers:     MaxPlayers,
+	MaxPlacedCells: 50,
+	MaxLobbies:     100,
+	MaxWorlds:      10,
+	BotDifficulty:  Normal,
+	MaxBots:        3,
 }
 
-const MaxPlayers = 10
-const MaxPlacedCells = 50
 const drawRate = 20
 const generationRate = 5
-const drawsPerGeneration = drawRate / generationRate
 
-const defaultWidth = 160
-const defaultHeight = 90
+// The leader at the end of each round is credited with a win. Rounds are
+// counted in generations, which is 5 minutes at the default speed, so faster
+// lobbies play shorter rounds and frozen ones don't end them.
+const roundLength = 5 * 60 * generationRate
+
+const pingDuration = 3 * time.Second
+
+// Each player can ping this often, so at most a few of theirs are shown
+const pingCooldown = 500 * time.Millisecond
+const emoteDuration = 5 * time.Second
+
+// Each emote is 2 columns wide to line up with a cell
+var Emotes = [...]string{"gg", ":)", ":(", "!!", "<3"}
 
 func (l *Lobby) PlayerCount() int {
-	// TODO mutex or atomic
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
 	return l.playerCount
 }
 
@@ -58,15 +142,21 @@ func (l *Lobby) Run() {
 	go func() {
 
 		var prevUpdate time.Time
-		iteration := 0
 
-		for now := range l.ticker.C {
-			iteration++
+		for {
+			var now time.Time
+			select {
+			case <-l.done:
+				l.ticker.Stop()
+				return
+			case now = <-l.ticker.C:
+			}
 
-			if iteration == drawsPerGeneration {
-				iteration = 0
+			l.updateMutex.Lock()
+			for i := l.due(); i > 0; i-- {
 				l.UpdateBoard()
 			}
+			l.updateMutex.Unlock()
 
 			l.Update(now.Sub(prevUpdate))
 
@@ -75,21 +165,26 @@ func (l *Lobby) Run() {
 	}()
 }
 
-func (l *Lobby) Join(playerId int, p *tea.Program) (*PlayerState, error) {
+func (l *Lobby) Join(playerId int, name string, c *Client) (*PlayerState, error) {
+	return l.join(playerId, name, c, false)
+}
+
+// join adds a player, marking bots before anyone else can see them
+func (l *Lobby) join(playerId int, name string, c *Client, bot bool) (*PlayerState, error) {
 	l.playersMutex.Lock()
 	defer l.playersMutex.Unlock()
 
-	if l.playerCount == MaxPlayers {
-		return nil, fmt.Errorf("Lobby has reached capacity of %v", MaxPlayers)
+	if l.playerCount >= l.settings.MaxPlayers {
+		return nil, fmt.Errorf("Lobby has reached capacity of %v", l.settings.MaxPlayers)
 	}
 
 	l.playerCount++
 
-	posX := rand.Intn(len(l.board))
+	posX := rand.Intn(len(l.board[0]))
 	posY := rand.Intn(len(l.board))
 
 	var color int
-	for i := 1; i <= 11; i++ {
+	for i := 1; i < len(l.playerColors); i++ {
 		if !l.playerColors[i] {
 			l.playerColors[i] = true
 			color = i
@@ -98,12 +193,15 @@ func (l *Lobby) Join(playerId int, p *tea.Program) (*PlayerState, error) {
 	}
 
 	ps := &PlayerState{
-		Id:      playerId,
-		Program: p,
-		PosX:    posX,
-		PosY:    posY,
-		Paused:  true,
-		Color:   color,
+		Id:     playerId,
+		Name:   name,
+		Client: c,
+		PosX:   posX,
+		PosY:   posY,
+		Paused: true,
+		Color:  color,
+		Joined: time.Now(),
+		Bot:    bot,
 	}
 
 	l.players[playerId] = ps
@@ -111,26 +209,94 @@ func (l *Lobby) Join(playerId int, p *tea.Program) (*PlayerState, error) {
 	return ps, nil
 }
 
-func (l *Lobby) Leave(playerId int) {
+// Leave removes the player and their cells, returning their final state
+func (l *Lobby) Leave(playerId int) *PlayerState {
 
 	l.playersMutex.Lock()
 	defer l.playersMutex.Unlock()
 	l.boardMutex.Lock()
 	defer l.boardMutex.Unlock()
 
+	ps, ok := l.players[playerId]
+	if !ok {
+		return nil
+	}
+
 	l.playerCount--
+	if !ps.Bot {
+		l.lastPlayed = time.Now()
+	}
 
-	l.playerColors[l.players[playerId].Color] = false
+	l.playerColors[ps.Color] = false
 	delete(l.players, playerId)
+	l.changed()
 
 	for y, row := range l.board {
 		for x, cell := range row {
-			if cell.Player == playerId {
-				l.board[y][x].Player = life.DeadPlayer
+			if cell.PausedPlayer == playerId {
 				l.board[y][x].PausedPlayer = life.DeadPlayer
 			}
+			if cell.Player == playerId {
+				// What you build in a world outlives you
+				if l.persistent {
+					l.board[y][x].Player = life.NeutralPlayer
+				} else {
+					l.board[y][x].Player = life.DeadPlayer
+				}
+			}
 		}
 	}
+
+	return ps
+}
+
+// PlayerNames returns the names of everyone in the lobby ordered by color
+func (l *Lobby) PlayerNames() []string {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+
+	var ps []*PlayerState
+	for _, p := range l.players {
+		ps = append(ps, p)
+	}
+	sort.Sort(byColor(ps))
+
+	names := make([]string, len(ps))
+	for i, p := range ps {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Detach keeps a disconnected player's state around so they can reconnect
+func (l *Lobby) Detach(playerId int) {
+	l.playersMutex.Lock()
+	defer l.playersMutex.Unlock()
+
+	ps, ok := l.players[playerId]
+	if !ok {
+		return
+	}
+	ps.Client = nil
+	ps.Disconnected = true
+}
+
+// Reattach gives a detached player a new client to draw to
+func (l *Lobby) Reattach(playerId int, c *Client) *PlayerState {
+	l.playersMutex.Lock()
+	defer l.playersMutex.Unlock()
+
+	ps, ok := l.players[playerId]
+	if !ok {
+		return nil
+	}
+	ps.Client = c
+	ps.Disconnected = false
+	return ps
+}
+
+func (l *Lobby) Settings() Settings {
+	return l.settings
 }
 
 func (l *Lobby) BoardSize() (int, int) {
@@ -157,12 +323,25 @@ func (s byCells) Less(i, j int) bool {
 	return s[i].Color < s[j].Color
 }
 
-var deadStyle = lipgloss.NewStyle().Background(lipgloss.Color(ColorTable[0].Cell))
+type byColor []*PlayerState
+
+func (s byColor) Len() int {
+	return len(s)
+}
+func (s byColor) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+func (s byColor) Less(i, j int) bool {
+	return s[i].Color < s[j].Color
+}
 
 func (l *Lobby) UpdateBoard() {
 
 	l.boardMutex.Lock()
 	l.board = life.NextBoard(l.board)
+	l.generation++
+	l.changed()
+	roundOver := l.generation%roundLength == 0
 	l.boardMutex.Unlock()
 
 	l.playersMutex.Lock()
@@ -170,27 +349,46 @@ func (l *Lobby) UpdateBoard() {
 		ps.Cells = 0
 	}
 
+	l.prunePings()
+
 	for _, row := range l.board {
 		for _, cell := range row {
-			if cell.Player != life.DeadPlayer {
-				l.players[cell.Player].Cells++
+			if ps, ok := l.players[cell.Player]; ok {
+				ps.Cells++
 			}
 		}
 	}
+
+	var leader *PlayerState
+	for _, ps := range l.players {
+		ps.History = Record(ps.History, ps.Cells)
+		if ps.Cells > ps.PeakCells {
+			ps.PeakCells = ps.Cells
+		}
+		if ps.Cells > 0 && (leader == nil || ps.Cells > leader.Cells) {
+			leader = ps
+		}
+	}
+	// Winning alone, or against only bots, doesn't count
+	if roundOver && leader != nil && l.humans() > 1 {
+		leader.RoundsWon++
+	}
 	l.playersMutex.Unlock()
 }
 
 func (l *Lobby) Update(delta time.Duration) {
 	l.playersMutex.RLock()
 	for _, player := range l.players {
-
-		player.Program.Send(UpdateBoardMsg{})
+		if player.Client == nil {
+			continue
+		}
+		player.Client.Notify()
 	}
 	l.playersMutex.RUnlock()
 
 }
 
-func (l *Lobby) Scoreboard() string {
+func (l *Lobby) Scoreboard(t *Theme) string {
 	l.playersMutex.RLock()
 	defer l.playersMutex.RUnlock()
 
@@ -202,97 +400,167 @@ func (l *Lobby) Scoreboard() string {
 	}
 	sort.Sort(sort.Reverse(byCells(ps)))
 
-	for _, p := range ps {
-		colorStyle := lipgloss.NewStyle().Background(lipgloss.Color(ColorTable[p.Color].Cell))
+	now := time.Now()
 
-		sb.WriteString(colorStyle.Render("  "))
+	for _, p := range ps {
+		sb.WriteString(t.Avatar(p.Color))
+		if p.Disconnected {
+			sb.WriteString("zz")
+		} else if p.Emote != "" && now.Before(p.emoteExpires) {
+			sb.WriteString(p.Emote)
+		} else {
+			sb.WriteString("  ")
+		}
 		sb.WriteString(" ")
-		sb.WriteString(fmt.Sprintf("%-5d", p.Cells))
+		sb.WriteString(fmt.Sprintf("%s %-5d", truncateName(p.Name), p.Cells))
 		sb.WriteString("  ")
 	}
 	return sb.String()
 }
 
-func (l *Lobby) ViewBoard(top, left, width, height int) string {
+const maxScoreboardName = 8
+
+func truncateName(name string) string {
+	if len(name) > maxScoreboardName {
+		return name[:maxScoreboardName-1] + "…"
+	}
+	return name
+}
+
+// ViewBoard renders width x height cells of the board for a session's theme.
+// At normal zoom, identified objects can be labeled with their names.
+func (l *Lobby) ViewBoard(t *Theme, zoom Zoom, labeled bool, top, left, width, height int) string {
+
+	boardWidth, boardHeight := l.BoardSize()
 
 	// Arbitrary limits to avoid unreasonable terminal sizes
 	// This already shows the board 4 times
-	if width > defaultWidth*2 {
-		width = defaultHeight * 2
+	if width > boardWidth*2 {
+		width = boardWidth * 2
 	}
-	if height > defaultHeight*2 {
-		height = defaultHeight * 2
+	if height > boardHeight*2 {
+		height = boardHeight * 2
 	}
 
-	sb := strings.Builder{}
-
-	boardWidth, boardHeight := l.BoardSize()
+	if zoom != ZoomNormal {
+		return l.viewZoomed(t, zoom, top, left, width, height)
+	}
 
 	l.playersMutex.RLock()
 	defer l.playersMutex.RUnlock()
 	l.boardMutex.RLock()
 	defer l.boardMutex.RUnlock()
 
+	cells := l.cachedRender(t)
+	marks := l.marks()
+	var labels map[[2]int]label
+	if labeled {
+		labels = l.labels()
+	}
+	labelBackground := lipgloss.Color(t.color(0).Cell)
+
+	sb := strings.Builder{}
+	w := rowWriter{t: t, sb: &sb}
+
 	for y := top; y < top+height; y++ {
-		boundY := (y + boardHeight) % boardHeight
+		boundY := util.Mod(y, boardHeight)
 
-		deadCount := 0
 		for x := left; x < left+width; x++ {
-			boundX := (x + boardWidth) % boardWidth
-			style := lipgloss.NewStyle()
-			pixel := "  "
+			boundX := util.Mod(x, boardWidth)
 
-			cursor := false
+			c := cells[boundY][boundX]
+			if m, ok := marks[[2]int{boundX, boundY}]; ok {
+				c = l.renderCell(t, l.board[boundY][boundX], m.cursor, m.ping)
+			} else if lb, ok := labels[[2]int{boundX, boundY}]; ok {
+				c = cellRender{look{lipgloss.Color(t.color(lb.color).Cursor), labelBackground, true}, lb.text}
+			}
+			w.write(c.look, c.text)
+		}
+		w.end()
 
-			for _, player := range l.players {
-				if boundY == player.PosY && boundX == player.PosX {
-					cursor = true
-					pixel = "[]"
-					style = style.Foreground(lipgloss.Color(ColorTable[player.Color].Cursor))
+		if y < top+height-1 {
+			sb.WriteString("\n")
+		}
+	}
 
-					break
-				}
-			}
+	return sb.String()
+}
 
-			if l.board[boundY][boundX].Player == life.DeadPlayer && l.board[boundY][boundX].PausedPlayer == life.DeadPlayer && !cursor {
-				deadCount++
-				continue
-			}
-			sb.WriteString(deadStyle.Render(strings.Repeat("  ", deadCount)))
-			deadCount = 0
+// mark is the color of a cursor or ping drawn over a cell
+type mark struct {
+	cursor int
+	ping   int
+}
 
-			if l.board[boundY][boundX].Player != life.DeadPlayer {
-				player, ok := l.players[l.board[boundY][boundX].Player]
-				if ok {
-					style = style.Background(lipgloss.Color(ColorTable[player.Color].Cell))
-				}
-			}
-			if l.board[boundY][boundX].PausedPlayer != life.DeadPlayer {
-				player, ok := l.players[l.board[boundY][boundX].PausedPlayer]
-				if ok {
-					if !cursor {
-						style = style.Foreground(lipgloss.Color(ColorTable[player.Color].Cell))
-						pixel = "::"
-					} else {
-						// style = style.Background(lipgloss.Color(ColorTable[fc].cell))
-						pixel = ":]"
-					}
-				}
-			}
-			sb.WriteString(style.Render(pixel))
-		}
+// marks finds every cursor and ping. Where several overlap, the lowest color
+// wins, so frames don't change at random. It must be called with
+// playersMutex held.
+func (l *Lobby) marks() map[[2]int]mark {
+	var ps []*PlayerState
+	for _, p := range l.players {
+		ps = append(ps, p)
+	}
+	sort.Sort(byColor(ps))
 
-		if deadCount > 0 {
-			sb.WriteString(deadStyle.Render(strings.Repeat("  ", deadCount)))
+	marks := make(map[[2]int]mark)
+	for _, p := range ps {
+		pos := [2]int{p.PosX, p.PosY}
+		if _, ok := marks[pos]; !ok {
+			marks[pos] = mark{cursor: p.Color}
 		}
+	}
 
-		sb.WriteString("\n")
+	now := time.Now()
+	for _, p := range l.pings {
+		pos := [2]int{p.posX, p.posY}
+		if _, ok := marks[pos]; !ok && now.Before(p.expires) {
+			marks[pos] = mark{ping: p.color}
+		}
 	}
+	return marks
+}
+
+// viewZoomed is ViewBoard for zoom levels with several cells per character.
+// Paused cells use their player's cursor color.
+func (l *Lobby) viewZoomed(t *Theme, zoom Zoom, top, left, width, height int) string {
+	boardWidth, boardHeight := l.BoardSize()
+
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+
+	marks := l.marks()
 
-	return sb.String()[:sb.Len()-1]
+	return RenderZoomed(t, zoom, width, height, func(x, y int) Pixel {
+		boundX := util.Mod(left+x, boardWidth)
+		boundY := util.Mod(top+y, boardHeight)
+
+		var p Pixel
+
+		if m, ok := marks[[2]int{boundX, boundY}]; ok {
+			color := m.cursor
+			if color == 0 {
+				color = m.ping
+			}
+			p.Cursor = lipgloss.Color(t.color(color).Cursor)
+		}
+
+		cell := l.board[boundY][boundX]
+		if player, ok := l.players[cell.Player]; ok {
+			p.Color = lipgloss.Color(t.color(player.Color).Cell)
+		} else if cell.Player == life.NeutralPlayer {
+			p.Color = lipgloss.Color(t.color(NeutralColor).Cell)
+		} else if player, ok := l.players[cell.PausedPlayer]; ok && cell.PausedPlayer != life.DeadPlayer {
+			p.Color = lipgloss.Color(t.color(player.Color).Cursor)
+		}
+		return p
+	})
 }
 
-func (l *Lobby) Place(id int) {
+// Place toggles a paused cell under the player's cursor and reports
+// whether the player has a cell there afterwards
+func (l *Lobby) Place(id int) bool {
 
 	l.playersMutex.RLock()
 	p, ok := l.players[id]
@@ -300,27 +568,56 @@ func (l *Lobby) Place(id int) {
 
 	// Maybe if player leaves but place() hasn't run yet?
 	if !ok {
-		return
+		return false
 	}
 	// Can only place in pause mode
 	if !p.Paused {
-		return
+		return false
 	}
 
 	l.boardMutex.Lock()
 	defer l.boardMutex.Unlock()
 
-	if l.board[p.PosY][p.PosX].PausedPlayer == life.DeadPlayer {
-		if p.Placed >= MaxPlacedCells {
-			return
+	alive := l.board[p.PosY][p.PosX].PausedPlayer != p.Id
+	return l.paint(p, alive)
+}
+
+// Paint places or removes a paused cell under the player's cursor, so
+// dragging over a cell twice doesn't undo it. It returns whether the player
+// has a paused cell there afterwards.
+func (l *Lobby) Paint(id int, alive bool) bool {
+	l.playersMutex.RLock()
+	p, ok := l.players[id]
+	l.playersMutex.RUnlock()
+
+	if !ok || !p.Paused {
+		return false
+	}
+
+	l.boardMutex.Lock()
+	defer l.boardMutex.Unlock()
+
+	return l.paint(p, alive)
+}
+
+// paint must be called with boardMutex held
+func (l *Lobby) paint(p *PlayerState, alive bool) bool {
+	cell := &l.board[p.PosY][p.PosX]
+
+	if alive && cell.PausedPlayer == life.DeadPlayer {
+		if p.Placed >= l.settings.MaxPlacedCells {
+			return false
 		}
-		l.board[p.PosY][p.PosX].PausedPlayer = p.Id
+		cell.PausedPlayer = p.Id
 		p.Placed++
+		l.changed()
 
-	} else if l.board[p.PosY][p.PosX].PausedPlayer == p.Id {
-		l.board[p.PosY][p.PosX].PausedPlayer = 0
+	} else if !alive && cell.PausedPlayer == p.Id {
+		cell.PausedPlayer = 0
 		p.Placed--
+		l.changed()
 	}
+	return cell.PausedPlayer == p.Id
 }
 
 func (l *Lobby) TogglePause(id int) {
@@ -354,6 +651,10 @@ func (l *Lobby) TogglePause(id int) {
 				}
 			}
 		}
+		if p.Placed > 0 {
+			p.PatternsPlaced++
+		}
+		l.changed()
 
 	} else {
 		for y, row := range l.board {
@@ -363,14 +664,129 @@ func (l *Lobby) TogglePause(id int) {
 				}
 			}
 		}
+		l.changed()
 	}
 
 	p.Paused = !p.Paused
 }
 
+// MoveCursor puts a player's cursor on a cell
+func (l *Lobby) MoveCursor(id, x, y int) {
+	l.playersMutex.Lock()
+	defer l.playersMutex.Unlock()
+
+	p, ok := l.players[id]
+	if !ok {
+		return
+	}
+	l.boardMutex.RLock()
+	width, height := len(l.board[0]), len(l.board)
+	l.boardMutex.RUnlock()
+	p.PosX = util.Mod(x, width)
+	p.PosY = util.Mod(y, height)
+}
+
+// Cursor is where a player's cursor is
+func (l *Lobby) Cursor(id int) (x, y int) {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+
+	if p, ok := l.players[id]; ok {
+		return p.PosX, p.PosY
+	}
+	return 0, 0
+}
+
 func (l *Lobby) GetPlayer(id int) *PlayerState {
 	// TODO i can't tell if this mutex is rlock is necessary
 	l.playersMutex.RLock()
 	defer l.playersMutex.RUnlock()
 	return l.players[id]
 }
+
+// Players copies everyone in the lobby, ordered by color
+func (l *Lobby) Players() []PlayerState {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+
+	var ps []*PlayerState
+	for _, p := range l.players {
+		ps = append(ps, p)
+	}
+	sort.Sort(byColor(ps))
+
+	players := make([]PlayerState, len(ps))
+	for i, p := range ps {
+		players[i] = *p
+	}
+	return players
+}
+
+// Board copies the board along with its generation
+func (l *Lobby) Board() (int, [][]life.Cell) {
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+
+	board := make([][]life.Cell, len(l.board))
+	for y, row := range l.board {
+		board[y] = make([]life.Cell, len(row))
+		copy(board[y], row)
+	}
+	return l.generation, board
+}
+
+// Ping drops a temporary marker at the player's cursor that everyone can see
+func (l *Lobby) Ping(id int) {
+	l.playersMutex.Lock()
+	defer l.playersMutex.Unlock()
+
+	p, ok := l.players[id]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if now.Sub(p.lastPing) < pingCooldown {
+		return
+	}
+	p.lastPing = now
+
+	// Pruned here too, since frozen lobbies don't update
+	l.prunePings()
+	l.pings = append(l.pings, ping{
+		posX:    p.PosX,
+		posY:    p.PosY,
+		color:   p.Color,
+		expires: now.Add(pingDuration),
+	})
+}
+
+// prunePings drops expired pings. It must be called with playersMutex held.
+func (l *Lobby) prunePings() {
+	now := time.Now()
+	pings := l.pings[:0]
+	for _, p := range l.pings {
+		if now.Before(p.expires) {
+			pings = append(pings, p)
+		}
+	}
+	l.pings = pings
+}
+
+// Emote shows Emotes[index] next to the player's avatar in the scoreboard
+func (l *Lobby) Emote(id int, index int) {
+	if index < 0 || index >= len(Emotes) {
+		return
+	}
+
+	l.playersMutex.Lock()
+	defer l.playersMutex.Unlock()
+
+	p, ok := l.players[id]
+	if !ok {
+		return
+	}
+
+	p.Emote = Emotes[index]
+	p.emoteExpires = time.Now().Add(emoteDuration)
+}
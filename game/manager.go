@@ -7,13 +7,22 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
 	petname "github.com/dustinkirkland/golang-petname"
 	"github.com/zhengkyl/gol/game/life"
+	"github.com/zhengkyl/gol/store"
+	"github.com/zhengkyl/gol/util"
 )
 
 type programState struct {
-	program *tea.Program
+	client  *Client
 	lobbyId int
+	// Empty for guests, who can't reconnect or be saved
+	key      string
+	nickname string
+	prefs    store.Prefs
+	// Set while a disconnected player can still reconnect
+	graceTimer *time.Timer
 }
 
 const (
@@ -21,6 +30,9 @@ const (
 	lobbyIdSolo = -1
 )
 
+// How long a dropped player keeps their spot in a lobby
+const reconnectGrace = 2 * time.Minute
+
 type Manager struct {
 	lobbies      map[int]*Lobby
 	lobbiesMutex sync.RWMutex
@@ -28,26 +40,68 @@ type Manager struct {
 	players      map[int]programState
 	playersMutex sync.RWMutex
 	playerId     int
+	store        *store.Store
+	worldsDir    string
+	settings     Settings
+	// Held while writing worlds, since saves come from a ticker, new worlds
+	// and shutdown
+	saveMutex sync.Mutex
+	// Closed to stop saving worlds
+	stopSaving chan struct{}
 }
 
-func NewManager() *Manager {
+// NewManager creates a manager that persists identities to st, which may be nil
+func NewManager(st *store.Store, settings Settings) *Manager {
 	return &Manager{
-		lobbies: make(map[int]*Lobby),
-		players: make(map[int]programState),
+		lobbies:  make(map[int]*Lobby),
+		players:  make(map[int]programState),
+		store:    st,
+		settings: settings,
 	}
 }
 
-func (gm *Manager) CreateLobby() int {
-	w, h := defaultWidth, defaultHeight
+func (gm *Manager) Settings() Settings {
+	return gm.settings
+}
 
-	l := &Lobby{
+func newLobby(name string, settings Settings) *Lobby {
+	return &Lobby{
 		players:      make(map[int]*PlayerState),
-		playerColors: [11]bool{true, false, false, false, false, false, false, false, false, false, false},
-		board:        life.NewBoard(w, h),
+		playerColors: [len(ColorTable)]bool{true},
+		board:        life.NewBoard(settings.Width, settings.Height),
 		ticker:       time.NewTicker(time.Second / drawRate),
-		name:         petname.Generate(2, "-"),
+		done:         make(chan struct{}),
+		lastPlayed:   time.Now(),
+		speed:        DefaultSpeed,
+		name:         name,
+		settings:     settings,
+	}
+}
+
+func (gm *Manager) checkLobbyLimit() error {
+	gm.lobbiesMutex.RLock()
+	defer gm.lobbiesMutex.RUnlock()
+
+	lobbies := 0
+	for _, l := range gm.lobbies {
+		if !l.persistent {
+			lobbies++
+		}
+	}
+	if lobbies >= gm.settings.MaxLobbies {
+		return fmt.Errorf("Server has reached capacity of %v lobbies", gm.settings.MaxLobbies)
 	}
+	return nil
+}
 
+func (gm *Manager) CreateLobby() (int, error) {
+	if err := gm.checkLobbyLimit(); err != nil {
+		return 0, err
+	}
+	return gm.addLobby(newLobby(petname.Generate(2, "-"), gm.settings)), nil
+}
+
+func (gm *Manager) addLobby(l *Lobby) int {
 	gm.lobbiesMutex.Lock()
 	gm.lobbyId++
 	l.id = gm.lobbyId
@@ -80,6 +134,8 @@ func (gm *Manager) CreateLobby() int {
 // }
 
 type SoloGameMsg struct{}
+type LeaderboardMsg struct{}
+type SettingsMsg struct{}
 type LobbyInfoList []LobbyInfo
 
 type LobbyInfo struct {
@@ -87,6 +143,8 @@ type LobbyInfo struct {
 	MaxPlayers  int
 	Name        string
 	Id          int
+	PlayerNames []string
+	Persistent  bool
 }
 
 func (gm *Manager) BroadcastLobbyInfos() {
@@ -94,10 +152,14 @@ func (gm *Manager) BroadcastLobbyInfos() {
 
 	gm.playersMutex.RLock()
 	for _, ps := range gm.players {
-		if ps.lobbyId == lobbyIdMenu {
+		if ps.lobbyId != lobbyIdMenu {
+			continue
+		}
+		// Clients without a program, like bots over the api, ask instead
+		if p := ps.client.Program(); p != nil {
 			go func(p *tea.Program) {
 				p.Send(infos)
-			}(ps.program)
+			}(p)
 		}
 	}
 	gm.playersMutex.RUnlock()
@@ -126,10 +188,12 @@ func (gm *Manager) LobbyInfos() []LobbyInfo {
 	gm.lobbiesMutex.RLock()
 	for _, l := range gm.lobbies {
 		infos = append(infos, LobbyInfo{
-			PlayerCount: l.playerCount,
-			MaxPlayers:  MaxPlayers,
+			PlayerCount: l.PlayerCount(),
+			MaxPlayers:  l.settings.MaxPlayers,
 			Name:        l.name,
 			Id:          l.id,
+			PlayerNames: l.PlayerNames(),
+			Persistent:  l.persistent,
 		})
 	}
 	gm.lobbiesMutex.RUnlock()
@@ -138,41 +202,205 @@ func (gm *Manager) LobbyInfos() []LobbyInfo {
 	return infos
 }
 
-func (gm *Manager) Connect(p *tea.Program) int {
+// Connect registers a started client for the identity key. nickname is only
+// used if the identity is new or there is no store. Guests have an empty key,
+// and nothing about them is saved.
+func (gm *Manager) Connect(c *Client, key, nickname string) int {
+	var prefs store.Prefs
+	if gm.store != nil && key != "" {
+		player, err := gm.store.Touch(key, nickname)
+		if err != nil {
+			log.Error("could not save player", "key", key, "err", err)
+		}
+		nickname = player.Nickname
+		prefs = player.Prefs
+	}
+
 	gm.playersMutex.Lock()
 	defer gm.playersMutex.Unlock()
 
+	// Only a verified identity can take back a held spot
+	for id, state := range gm.players {
+		if key == "" || state.key != key || state.graceTimer == nil {
+			continue
+		}
+		// Too late if the timer already fired
+		if !state.graceTimer.Stop() {
+			continue
+		}
+
+		gm.lobbiesMutex.RLock()
+		lobby, ok := gm.lobbies[state.lobbyId]
+		gm.lobbiesMutex.RUnlock()
+		if ok {
+			lobby.Reattach(id, c)
+		}
+
+		state.client = c
+		state.graceTimer = nil
+		gm.players[id] = state
+		return id
+	}
+
 	gm.playerId++
 
 	gm.players[gm.playerId] = programState{
-		program: p,
-		lobbyId: lobbyIdMenu,
+		client:   c,
+		lobbyId:  lobbyIdMenu,
+		key:      key,
+		nickname: nickname,
+		prefs:    prefs,
 	}
 
 	return gm.playerId
 }
 
+func (gm *Manager) Prefs(playerId int) store.Prefs {
+	gm.playersMutex.RLock()
+	defer gm.playersMutex.RUnlock()
+	return gm.players[playerId].prefs
+}
+
+func (gm *Manager) SetPrefs(playerId int, prefs store.Prefs) error {
+	gm.playersMutex.Lock()
+	defer gm.playersMutex.Unlock()
+
+	state, ok := gm.players[playerId]
+	if !ok {
+		return fmt.Errorf("player with id=%v does not exist", playerId)
+	}
+
+	if gm.store != nil && state.key != "" {
+		if err := gm.store.SetPrefs(state.key, prefs); err != nil {
+			return err
+		}
+	}
+
+	state.prefs = prefs
+	gm.players[playerId] = state
+	return nil
+}
+
+func (gm *Manager) Nickname(playerId int) string {
+	gm.playersMutex.RLock()
+	defer gm.playersMutex.RUnlock()
+	return gm.players[playerId].nickname
+}
+
+func (gm *Manager) SetNickname(playerId int, nickname string) error {
+	if err := store.ValidateNickname(nickname); err != nil {
+		return err
+	}
+
+	gm.playersMutex.Lock()
+	defer gm.playersMutex.Unlock()
+
+	state, ok := gm.players[playerId]
+	if !ok {
+		return fmt.Errorf("player with id=%v does not exist", playerId)
+	}
+
+	if gm.store != nil && state.key != "" {
+		if err := gm.store.SetNickname(state.key, nickname); err != nil {
+			return err
+		}
+	}
+
+	state.nickname = nickname
+	gm.players[playerId] = state
+	return nil
+}
+
 func (gm *Manager) Disconnect(playerId int) {
 	gm.playersMutex.Lock()
 	state, ok := gm.players[playerId]
-	gm.playersMutex.Unlock()
 
 	if !ok {
+		gm.playersMutex.Unlock()
 		return // maybe disconnect before connect? idk if possible
 	}
 
+	// Hold the spot in case the connection just dropped. Guests couldn't
+	// prove it's them coming back, so theirs isn't held.
+	if state.lobbyId >= 0 && state.key != "" {
+		gm.lobbiesMutex.RLock()
+		lobby, ok := gm.lobbies[state.lobbyId]
+		gm.lobbiesMutex.RUnlock()
+
+		if ok {
+			lobby.Detach(playerId)
+			state.client.Close()
+			state.client = nil
+			state.graceTimer = time.AfterFunc(reconnectGrace, func() {
+				gm.expire(playerId, state.lobbyId)
+			})
+			gm.players[playerId] = state
+			gm.playersMutex.Unlock()
+			return
+		}
+	}
+	gm.playersMutex.Unlock()
+
+	if state.client != nil {
+		state.client.Close()
+	}
 	if state.lobbyId >= 0 {
 		gm.removeFromLobby(state.lobbyId, playerId)
 	}
 
+	gm.playersMutex.Lock()
 	delete(gm.players, playerId)
+	gm.playersMutex.Unlock()
+}
+
+// expire removes a disconnected player who didn't reconnect in time
+func (gm *Manager) expire(playerId, lobbyId int) {
+	gm.removeFromLobby(lobbyId, playerId)
+
+	gm.playersMutex.Lock()
+	delete(gm.players, playerId)
+	gm.playersMutex.Unlock()
+}
+
+// Resume returns a JoinSuccessMsg if the player reconnected into a lobby
+func (gm *Manager) Resume(playerId int) tea.Msg {
+	gm.playersMutex.RLock()
+	state, ok := gm.players[playerId]
+	gm.playersMutex.RUnlock()
+
+	if !ok || state.lobbyId < 0 {
+		return nil
+	}
+
+	gm.lobbiesMutex.RLock()
+	lobby, ok := gm.lobbies[state.lobbyId]
+	gm.lobbiesMutex.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	ps := lobby.GetPlayer(playerId)
+	if ps == nil {
+		return nil
+	}
+
+	bw, bh := lobby.BoardSize()
+	return JoinSuccessMsg{
+		Lobby:       lobby,
+		PlayerState: ps,
+		BoardWidth:  bw,
+		BoardHeight: bh,
+	}
 }
 
 func (gm *Manager) LeaveLobby(playerId int) {
 	gm.playersMutex.Lock()
 	state, ok := gm.players[playerId]
+	lobbyId := state.lobbyId
 	if ok {
-		gm.players[playerId] = programState{lobbyId: lobbyIdMenu, program: state.program}
+		state.lobbyId = lobbyIdMenu
+		gm.players[playerId] = state
 	}
 	gm.playersMutex.Unlock()
 
@@ -180,8 +408,8 @@ func (gm *Manager) LeaveLobby(playerId int) {
 		return
 	}
 
-	if state.lobbyId >= 0 {
-		gm.removeFromLobby(state.lobbyId, playerId)
+	if lobbyId >= 0 {
+		gm.removeFromLobby(lobbyId, playerId)
 	}
 }
 
@@ -196,6 +424,10 @@ type JoinFailMsg struct {
 	err string
 }
 
+func (m JoinFailMsg) Error() string {
+	return m.err
+}
+
 func (gm *Manager) JoinLobby(lobbyId int, playerId int) tea.Msg {
 	gm.lobbiesMutex.RLock()
 	lobby, ok := gm.lobbies[lobbyId]
@@ -207,14 +439,19 @@ func (gm *Manager) JoinLobby(lobbyId int, playerId int) tea.Msg {
 
 	gm.playersMutex.Lock()
 
-	program := gm.players[playerId].program
-	ps, err := lobby.Join(playerId, program)
+	state := gm.players[playerId]
+	ps, err := lobby.Join(playerId, state.nickname, state.client)
+	// Bots give up their spot to people
+	if err != nil && lobby.kickBot() {
+		ps, err = lobby.Join(playerId, state.nickname, state.client)
+	}
 	if err != nil {
 		gm.playersMutex.Unlock()
 		return JoinFailMsg{err.Error()}
 	}
 
-	gm.players[playerId] = programState{program: program, lobbyId: lobbyId}
+	state.lobbyId = lobbyId
+	gm.players[playerId] = state
 
 	gm.playersMutex.Unlock()
 	bw, bh := lobby.BoardSize()
@@ -239,16 +476,113 @@ func (gm *Manager) removeFromLobby(lobbyId, playerId int) {
 		return
 	}
 
-	lobby.Leave(playerId)
-	count := lobby.playerCount
+	ps := lobby.Leave(playerId)
+	// Bots don't play to an empty lobby
+	if lobby.Humans() == 0 {
+		for _, id := range lobby.Bots() {
+			lobby.Leave(id)
+		}
+	}
+	count := lobby.PlayerCount()
+	gm.lobbiesMutex.RUnlock()
+
+	if ps != nil && !ps.Bot {
+		gm.saveStats(playerId, ps)
+	}
+
+	if count == 0 && !lobby.persistent {
+		gm.deleteLobby(lobbyId)
+	}
+
+	gm.BroadcastLobbyInfos()
+
+}
+
+// deleteLobby removes a lobby and stops it running
+func (gm *Manager) deleteLobby(lobbyId int) {
+	gm.lobbiesMutex.Lock()
+	lobby, ok := gm.lobbies[lobbyId]
+	delete(gm.lobbies, lobbyId)
+	gm.lobbiesMutex.Unlock()
+
+	if ok {
+		close(lobby.done)
+	}
+}
+
+// ToggleBots fills the empty spots in the host's lobby with bots, or removes
+// them if there are any
+func (gm *Manager) ToggleBots(playerId int) {
+	gm.playersMutex.RLock()
+	state, ok := gm.players[playerId]
+	gm.playersMutex.RUnlock()
+
+	if !ok || state.lobbyId < 0 {
+		return
+	}
+
+	gm.lobbiesMutex.RLock()
+	lobby, ok := gm.lobbies[state.lobbyId]
 	gm.lobbiesMutex.RUnlock()
 
-	if count == 0 {
-		gm.lobbiesMutex.Lock()
-		delete(gm.lobbies, lobbyId)
-		gm.lobbiesMutex.Unlock()
+	if !ok || lobby.Host() != playerId {
+		return
+	}
+
+	if bots := lobby.Bots(); len(bots) > 0 {
+		for _, id := range bots {
+			lobby.Leave(id)
+		}
+		gm.BroadcastLobbyInfos()
+		return
 	}
 
+	n := util.Min(gm.settings.MaxBots, lobby.settings.MaxPlayers-lobby.PlayerCount())
+	for i := 0; i < n; i++ {
+		// Bots share ids with players so they can't collide
+		gm.playersMutex.Lock()
+		gm.playerId++
+		id := gm.playerId
+		gm.playersMutex.Unlock()
+
+		strategy := Strategy(i % int(numStrategies))
+		if err := lobby.addBot(id, strategy, gm.settings.BotDifficulty); err != nil {
+			break
+		}
+	}
 	gm.BroadcastLobbyInfos()
+}
 
+func (gm *Manager) saveStats(playerId int, ps *PlayerState) {
+	if gm.store == nil {
+		return
+	}
+
+	gm.playersMutex.RLock()
+	key := gm.players[playerId].key
+	gm.playersMutex.RUnlock()
+
+	if key == "" {
+		return
+	}
+	err := gm.store.UpdateStats(key, func(s *store.Stats) {
+		s.GamesPlayed++
+		if ps.PeakCells > s.PeakCells {
+			s.PeakCells = ps.PeakCells
+		}
+		s.RoundsWon += ps.RoundsWon
+		s.PatternsPlaced += ps.PatternsPlaced
+		s.TimePlayed += time.Since(ps.Joined)
+	})
+	if err != nil {
+		log.Error("could not save stats", "key", key, "err", err)
+	}
+}
+
+// Leaderboard returns the top n players of all time
+func (gm *Manager) Leaderboard(n int) []store.Player {
+	if gm.store == nil {
+		return nil
+	}
+	return gm.store.Leaderboard(n)
 }
@@ -0,0 +1,40 @@
+package game
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startedClient is never run, so lobby lists sent to it are dropped
+func startedClient() *Client {
+	c := NewClient()
+	c.Start(tea.NewProgram(nil))
+	return c
+}
+
+func TestReconnect(t *testing.T) {
+	gm := NewManager(nil, DefaultSettings)
+	lobbyId, err := gm.CreateLobby()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	player := gm.Connect(startedClient(), "key:a", "player")
+	guest := gm.Connect(startedClient(), "", "guest")
+	gm.JoinLobby(lobbyId, player)
+	gm.JoinLobby(lobbyId, guest)
+
+	gm.Disconnect(player)
+	gm.Disconnect(guest)
+
+	if id := gm.Connect(startedClient(), "", "guest"); id == guest {
+		t.Error("a guest took back a spot")
+	}
+	if id := gm.Connect(startedClient(), "key:b", "player"); id == player {
+		t.Error("another key took back a spot")
+	}
+	if id := gm.Connect(startedClient(), "key:a", "player"); id != player {
+		t.Errorf("got id %v reconnecting, want %v", id, player)
+	}
+}
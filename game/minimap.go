@@ -0,0 +1,127 @@
+package game
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zhengkyl/gol/game/life"
+	"github.com/zhengkyl/gol/util"
+)
+
+// MinimapScale returns how many cells wide each minimap dot is, so the
+// whole board fits in maxWidth columns
+func MinimapScale(boardWidth, maxWidth int) int {
+	if maxWidth < 1 {
+		maxWidth = 1
+	}
+	return (boardWidth + 2*maxWidth - 1) / (2 * maxWidth)
+}
+
+// MinimapSize returns the size in columns and rows of a minimap drawn at scale
+func MinimapSize(boardWidth, boardHeight, scale int) (int, int) {
+	return (boardWidth + 2*scale - 1) / (2 * scale), (boardHeight + 4*scale - 1) / (4 * scale)
+}
+
+// ViewMinimap renders the whole board, with each dot covering scale x scale
+// cells. Characters are colored by whoever owns most of their cells. Cursors
+// are marked with their player's color and the viewport is outlined.
+func (l *Lobby) ViewMinimap(t *Theme, scale, top, left, width, height int) string {
+	boardWidth, boardHeight := l.BoardSize()
+	cols, rows := MinimapSize(boardWidth, boardHeight, scale)
+
+	background := lipgloss.Color(t.color(0).Cell)
+	outline := lipgloss.Color("240")
+
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+
+	var ps []*PlayerState
+	for _, p := range l.players {
+		ps = append(ps, p)
+	}
+	sort.Sort(byColor(ps))
+
+	// Where each player's cursor is, in characters
+	cursors := make(map[[2]int]int)
+	for _, p := range ps {
+		pos := [2]int{p.PosX / (2 * scale), p.PosY / (4 * scale)}
+		if _, ok := cursors[pos]; !ok {
+			cursors[pos] = p.Color
+		}
+	}
+
+	// covers reports whether the n cells from start include cell c
+	covers := func(start, n, c, size int) bool {
+		return util.Mod(c-start, size) < n
+	}
+
+	sb := strings.Builder{}
+	w := rowWriter{t: t, sb: &sb}
+	owners := make(map[int]int)
+
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			x0, y0 := col*2*scale, row*4*scale
+
+			char := rune(brailleBlank)
+			for k := range owners {
+				delete(owners, k)
+			}
+
+			for dy := 0; dy < 4; dy++ {
+				for dx := 0; dx < 2; dx++ {
+					for y := y0 + dy*scale; y < y0+(dy+1)*scale && y < boardHeight; y++ {
+						for x := x0 + dx*scale; x < x0+(dx+1)*scale && x < boardWidth; x++ {
+							if id := l.board[y][x].Player; id != life.DeadPlayer {
+								char |= brailleDots[dy][dx]
+								owners[id]++
+							}
+						}
+					}
+				}
+			}
+
+			lk := look{bg: background}
+
+			dominant, most := life.DeadPlayer, 0
+			for id, n := range owners {
+				if n > most || (n == most && id < dominant) {
+					dominant, most = id, n
+				}
+			}
+			if p, ok := l.players[dominant]; ok {
+				lk.fg = lipgloss.Color(t.color(p.Color).Cell)
+			} else if dominant == life.NeutralPlayer {
+				lk.fg = lipgloss.Color(t.color(NeutralColor).Cell)
+			}
+
+			// The viewport's edges, which may wrap around the board
+			spanX, spanY := 2*scale, 4*scale
+			inX := covers(left, width, x0, boardWidth) || covers(x0, spanX, left, boardWidth)
+			inY := covers(top, height, y0, boardHeight) || covers(y0, spanY, top, boardHeight)
+			edgeX := covers(x0, spanX, left, boardWidth) || covers(x0, spanX, left+width-1, boardWidth)
+			edgeY := covers(y0, spanY, top, boardHeight) || covers(y0, spanY, top+height-1, boardHeight)
+			if (edgeX && inY) || (edgeY && inX) {
+				lk.bg = outline
+			}
+
+			pixel := string(char)
+			if color, ok := cursors[[2]int{col, row}]; ok {
+				pixel = "◆"
+				lk.fg = lipgloss.Color(t.color(color).Cursor)
+				lk.bold = true
+			}
+
+			w.write(lk, pixel)
+		}
+		w.end()
+		if row < rows-1 {
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
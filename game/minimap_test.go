@@ -0,0 +1,37 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/zhengkyl/gol/game/life"
+)
+
+func TestViewMinimap(t *testing.T) {
+	settings := DefaultSettings
+	settings.Width, settings.Height = 8, 8
+	l := newLobby("test", settings)
+	l.board[0][0].Player = life.NeutralPlayer
+	l.board[5][3].Player = life.NeutralPlayer
+
+	r := lipgloss.NewRenderer(nil)
+	r.SetColorProfile(termenv.Ascii)
+	theme := &Theme{Renderer: r}
+
+	// Each character covers 2x4 cells
+	got := l.ViewMinimap(theme, 1, 0, 0, 8, 8)
+	want := "⠁⠀⠀⠀\n⠀⠐⠀⠀"
+	if got != want {
+		t.Errorf("got\n%q\nwant\n%q", got, want)
+	}
+
+	cols, rows := MinimapSize(160, 90, MinimapScale(160, 40))
+	if cols != 40 || rows != 12 {
+		t.Errorf("got %vx%v minimap, want 40x12", cols, rows)
+	}
+	if lines := strings.Count(got, "\n") + 1; lines != 2 {
+		t.Errorf("got %v lines, want 2", lines)
+	}
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zhengkyl/gol/game/analysis"
+	"github.com/zhengkyl/gol/game/life"
+	"github.com/zhengkyl/gol/util"
+)
+
+// CensusRows is how many players the object census shows
+const CensusRows = 5
+
+// objectsCache is the last analysis of the board
+type objectsCache struct {
+	version int
+	objects []analysis.Object
+}
+
+// objects identifies everything on the board, reusing the last result until
+// the board changes. It must be called with boardMutex held for reading.
+func (l *Lobby) objects() []analysis.Object {
+	l.renderMutex.Lock()
+	defer l.renderMutex.Unlock()
+
+	if l.analyzed == nil || l.analyzed.version != l.version {
+		l.analyzed = &objectsCache{l.version, analysis.Identify(l.board)}
+	}
+	return l.analyzed.objects
+}
+
+// Objects identifies everything on the board
+func (l *Lobby) Objects() []analysis.Object {
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+	return l.objects()
+}
+
+// label is part of an object's name drawn over a cell
+type label struct {
+	text  string
+	color int
+}
+
+// labels names each identified object on the row above it, 2 characters per
+// cell. It must be called with playersMutex and boardMutex held.
+func (l *Lobby) labels() map[[2]int]label {
+	boardWidth, boardHeight := len(l.board[0]), len(l.board)
+	labels := make(map[[2]int]label)
+
+	for _, o := range l.objects() {
+		if o.Pattern == nil {
+			continue
+		}
+		color := NeutralColor
+		if p, ok := l.players[o.Player]; ok {
+			color = p.Color
+		} else if o.Player != life.NeutralPlayer {
+			continue
+		}
+
+		name := o.Pattern.Name
+		if len(name)%2 != 0 {
+			name += " "
+		}
+		y := util.Mod(o.Y-1, boardHeight)
+		for i := 0; i < len(name); i += 2 {
+			pos := [2]int{util.Mod(o.X+i/2, boardWidth), y}
+			// Earlier objects keep their labels where they overlap
+			if _, ok := labels[pos]; !ok {
+				labels[pos] = label{name[i : i+2], color}
+			}
+		}
+	}
+	return labels
+}
+
+// ViewCensus lists the identified objects of the CensusRows players with the
+// most cells, most common first
+func (l *Lobby) ViewCensus(t *Theme, width int) string {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+
+	census := analysis.Census(l.objects())
+
+	var ps []*PlayerState
+	for _, p := range l.players {
+		ps = append(ps, p)
+	}
+	sort.Sort(sort.Reverse(byCells(ps)))
+	if len(ps) > CensusRows {
+		ps = ps[:CensusRows]
+	}
+
+	style := t.Renderer.NewStyle().Inline(true).MaxWidth(util.Max(0, width-2-1-maxScoreboardName-1))
+	rows := make([]string, CensusRows)
+	for i, p := range ps {
+		var counts []string
+		for _, c := range analysis.Sorted(census[p.Id]) {
+			counts = append(counts, fmt.Sprintf("%d %s", c.N, c.Name))
+		}
+		if len(counts) == 0 {
+			counts = append(counts, "nothing known")
+		}
+
+		rows[i] = fmt.Sprintf("%s %-*s %s", t.Avatar(p.Color), maxScoreboardName, truncateName(p.Name), style.Render(strings.Join(counts, ", ")))
+	}
+	return strings.Join(rows, "\n")
+}
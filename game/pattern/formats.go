@@ -0,0 +1,333 @@
+package pattern
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rleLineLength is how long RLE lines are kept, as most programs expect
+const rleLineLength = 70
+
+// readRLE reads run length encoded patterns like
+//
+//	#N Glider
+//	x = 3, y = 3, rule = B3/S23
+//	bob$2bo$3o!
+func readRLE(text string) (*Pattern, error) {
+	p := &Pattern{}
+	var body strings.Builder
+	header := false
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "#"):
+			if len(line) < 2 {
+				continue
+			}
+			rest := strings.TrimSpace(line[2:])
+			switch line[1] {
+			case 'N':
+				p.Name = rest
+			case 'C', 'c':
+				p.Comments = append(p.Comments, rest)
+			}
+		case !header && strings.HasPrefix(line, "x"):
+			header = true
+			for _, field := range strings.Split(line, ",") {
+				kv := strings.SplitN(field, "=", 2)
+				if len(kv) != 2 {
+					return nil, fmt.Errorf("rle: invalid header %q", line)
+				}
+				value := strings.TrimSpace(kv[1])
+				var err error
+				switch strings.TrimSpace(kv[0]) {
+				case "x":
+					p.Width, err = strconv.Atoi(value)
+				case "y":
+					p.Height, err = strconv.Atoi(value)
+				case "rule":
+					p.Rule = value
+				}
+				if err != nil || p.Width < 0 || p.Height < 0 {
+					return nil, fmt.Errorf("rle: invalid size in header %q", line)
+				}
+				if p.Width > MaxSize || p.Height > MaxSize {
+					return nil, fmt.Errorf("rle: size in header %q is bigger than %vx%v", line, MaxSize, MaxSize)
+				}
+			}
+		default:
+			body.WriteString(line)
+		}
+	}
+	if !header {
+		return nil, fmt.Errorf("rle: missing x = ..., y = ... header")
+	}
+
+	x, y, run := 0, 0, 0
+	for _, c := range body.String() {
+		if c >= '0' && c <= '9' {
+			run = run*10 + int(c-'0')
+			if run > MaxSize {
+				return nil, fmt.Errorf("rle: run longer than %v", MaxSize)
+			}
+			continue
+		}
+		if c == '!' {
+			p.grow()
+			return p, nil
+		}
+
+		n := run
+		if n == 0 {
+			n = 1
+		}
+		run = 0
+
+		switch {
+		case c == 'b' || c == '.':
+			x += n
+		case c == '$':
+			x = 0
+			y += n
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			// Checked here too, since cells are kept as they're read
+			if x+n > MaxSize || y >= MaxSize {
+				return nil, fmt.Errorf("rle: pattern is bigger than %vx%v", MaxSize, MaxSize)
+			}
+			// Extra states from other rules count as alive
+			for i := 0; i < n; i++ {
+				p.Cells = append(p.Cells, [2]int{x, y})
+				x++
+			}
+		default:
+			return nil, fmt.Errorf("rle: unexpected %q", c)
+		}
+	}
+	return nil, fmt.Errorf("rle: missing ! at the end")
+}
+
+func writeRLE(p *Pattern) string {
+	sb := strings.Builder{}
+	if p.Name != "" {
+		sb.WriteString("#N " + p.Name + "\n")
+	}
+	for _, c := range p.Comments {
+		sb.WriteString("#C " + c + "\n")
+	}
+	rule := p.Rule
+	if rule == "" {
+		rule = "B3/S23"
+	}
+	sb.WriteString(fmt.Sprintf("x = %d, y = %d, rule = %s\n", p.Width, p.Height, rule))
+
+	// Runs are written as tokens, then wrapped into lines
+	var tokens []string
+	add := func(n int, tag byte) {
+		if n == 0 {
+			return
+		}
+		if n == 1 {
+			tokens = append(tokens, string(tag))
+		} else {
+			tokens = append(tokens, strconv.Itoa(n)+string(tag))
+		}
+	}
+
+	rows := 0
+	for _, row := range p.grid() {
+		end := len(row)
+		for end > 0 && !row[end-1] {
+			end--
+		}
+		// Empty rows are part of the next row's $ run
+		if end == 0 {
+			rows++
+			continue
+		}
+		add(rows, '$')
+		rows = 1
+
+		for x := 0; x < end; {
+			alive := row[x]
+			n := 0
+			for x < end && row[x] == alive {
+				n++
+				x++
+			}
+			if alive {
+				add(n, 'o')
+			} else {
+				add(n, 'b')
+			}
+		}
+	}
+	tokens = append(tokens, "!")
+
+	line := 0
+	for _, t := range tokens {
+		if line+len(t) > rleLineLength {
+			sb.WriteString("\n")
+			line = 0
+		}
+		sb.WriteString(t)
+		line += len(t)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// readCells reads plaintext patterns like
+//
+//	!Name: Glider
+//	.O.
+//	..O
+//	OOO
+func readCells(text string) (*Pattern, error) {
+	p := &Pattern{}
+	y := 0
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "!") {
+			comment := strings.TrimSpace(line[1:])
+			if name, ok := trimPrefix(comment, "Name:"); ok {
+				p.Name = strings.TrimSpace(name)
+			} else if comment != "" {
+				p.Comments = append(p.Comments, comment)
+			}
+			continue
+		}
+
+		line = strings.TrimRight(line, " \t")
+		for x, c := range line {
+			switch c {
+			case 'O', 'o', '*':
+				p.Cells = append(p.Cells, [2]int{x, y})
+			case '.':
+			default:
+				return nil, fmt.Errorf("cells: unexpected %q on line %d", c, y+1)
+			}
+		}
+		y++
+
+		// Blank lines only count as rows when there's more after them
+		if line != "" {
+			p.Height = y
+		}
+		if len(line) > p.Width {
+			p.Width = len(line)
+		}
+	}
+	return p, nil
+}
+
+func trimPrefix(s, prefix string) (string, bool) {
+	if strings.HasPrefix(s, prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+func writeCells(p *Pattern) string {
+	sb := strings.Builder{}
+	if p.Name != "" {
+		sb.WriteString("!Name: " + p.Name + "\n")
+	}
+	for _, c := range p.Comments {
+		sb.WriteString("!" + c + "\n")
+	}
+	for _, row := range p.grid() {
+		end := len(row)
+		for end > 0 && !row[end-1] {
+			end--
+		}
+		for _, alive := range row[:end] {
+			if alive {
+				sb.WriteByte('O')
+			} else {
+				sb.WriteByte('.')
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// maxCoordinate is the furthest out Life 1.06 cells can be
+const maxCoordinate = 1 << 30
+
+// readLife106 reads lists of live cell coordinates like
+//
+//	#Life 1.06
+//	0 -1
+//	1 0
+func readLife106(text string) (*Pattern, error) {
+	p := &Pattern{}
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if rest, ok := trimPrefix(line, "#"); ok {
+			if name, ok := trimPrefix(rest, "N"); ok {
+				p.Name = strings.TrimSpace(name)
+			} else if comment, ok := trimPrefix(rest, "C"); ok {
+				p.Comments = append(p.Comments, strings.TrimSpace(comment))
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("life106: invalid line %d %q", i+1, line)
+		}
+		x, errX := strconv.Atoi(fields[0])
+		y, errY := strconv.Atoi(fields[1])
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("life106: invalid line %d %q", i+1, line)
+		}
+		// Small enough that sizes can't overflow
+		if x < -maxCoordinate || x > maxCoordinate || y < -maxCoordinate || y > maxCoordinate {
+			return nil, fmt.Errorf("life106: line %d %q is too far out", i+1, line)
+		}
+		p.Cells = append(p.Cells, [2]int{x, y})
+	}
+	p.fit()
+	return p, nil
+}
+
+func writeLife106(p *Pattern) string {
+	sb := strings.Builder{}
+	sb.WriteString("#Life 1.06\n")
+	if p.Name != "" {
+		sb.WriteString("#N " + p.Name + "\n")
+	}
+	for _, c := range p.Comments {
+		sb.WriteString("#C " + c + "\n")
+	}
+
+	// Row by row, like the other formats
+	cells := append([][2]int(nil), p.Cells...)
+	sort.Sort(byPosition(cells))
+	for _, c := range cells {
+		sb.WriteString(fmt.Sprintf("%d %d\n", c[0], c[1]))
+	}
+	return sb.String()
+}
+
+type byPosition [][2]int
+
+func (s byPosition) Len() int {
+	return len(s)
+}
+func (s byPosition) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+func (s byPosition) Less(i, j int) bool {
+	if s[i][1] != s[j][1] {
+		return s[i][1] < s[j][1]
+	}
+	return s[i][0] < s[j][0]
+}
@@ -0,0 +1,168 @@
+// Package pattern reads and writes patterns in common Life file formats
+package pattern
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/zhengkyl/gol/game/life"
+)
+
+// Pattern is a set of live cells
+type Pattern struct {
+	Name     string
+	Comments []string
+	// Rule in B/S notation, or empty if the file didn't say
+	Rule          string
+	Width, Height int
+	// Live cells, relative to the top left corner
+	Cells [][2]int
+}
+
+// MaxSize is the most cells wide or tall a pattern can be, so a file can't
+// make readers allocate huge grids
+const MaxSize = 2048
+
+// Formats are the names of every supported format
+var Formats = []string{"rle", "cells", "life106"}
+
+// FormatFor guesses a file's format from its extension
+func FormatFor(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".rle":
+		return "rle", nil
+	case ".cells", ".txt":
+		return "cells", nil
+	case ".lif", ".life":
+		return "life106", nil
+	}
+	return "", fmt.Errorf("can't tell the format of %v, use one of %v", path, strings.Join(Formats, ", "))
+}
+
+// Read parses a pattern in a format
+func Read(r io.Reader, format string) (*Pattern, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// Files from Windows are common
+	text := strings.ReplaceAll(string(b), "\r\n", "\n")
+
+	var p *Pattern
+	switch format {
+	case "rle":
+		p, err = readRLE(text)
+	case "cells":
+		p, err = readCells(text)
+	case "life106":
+		p, err = readLife106(text)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Sizes that overflowed come out negative
+	if p.Width < 0 || p.Height < 0 || p.Width > MaxSize || p.Height > MaxSize {
+		return nil, fmt.Errorf("%v: pattern is %vx%v, bigger than %vx%v", format, p.Width, p.Height, MaxSize, MaxSize)
+	}
+	return p, nil
+}
+
+// Write formats a pattern
+func Write(w io.Writer, p *Pattern, format string) error {
+	var text string
+	switch format {
+	case "rle":
+		text = writeRLE(p)
+	case "cells":
+		text = writeCells(p)
+	case "life106":
+		text = writeLife106(p)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	_, err := io.WriteString(w, text)
+	return err
+}
+
+// fit moves cells so the top left one is at 0, 0 and sets the size
+func (p *Pattern) fit() {
+	if len(p.Cells) == 0 {
+		return
+	}
+	minX, minY := p.Cells[0][0], p.Cells[0][1]
+	maxX, maxY := minX, minY
+	for _, c := range p.Cells {
+		if c[0] < minX {
+			minX = c[0]
+		}
+		if c[1] < minY {
+			minY = c[1]
+		}
+		if c[0] > maxX {
+			maxX = c[0]
+		}
+		if c[1] > maxY {
+			maxY = c[1]
+		}
+	}
+	for i := range p.Cells {
+		p.Cells[i][0] -= minX
+		p.Cells[i][1] -= minY
+	}
+	p.Width = maxX - minX + 1
+	p.Height = maxY - minY + 1
+}
+
+// grow makes the size big enough for every cell
+func (p *Pattern) grow() {
+	for _, c := range p.Cells {
+		if c[0] >= p.Width {
+			p.Width = c[0] + 1
+		}
+		if c[1] >= p.Height {
+			p.Height = c[1] + 1
+		}
+	}
+}
+
+// grid returns which cells are alive, indexed by row then column
+func (p *Pattern) grid() [][]bool {
+	grid := make([][]bool, p.Height)
+	for y := range grid {
+		grid[y] = make([]bool, p.Width)
+	}
+	for _, c := range p.Cells {
+		grid[c[1]][c[0]] = true
+	}
+	return grid
+}
+
+// Place draws the pattern onto a board with its top left corner at left, top,
+// wrapping around the edges
+func (p *Pattern) Place(board [][]life.Cell, left, top, player int) {
+	height := len(board)
+	width := len(board[0])
+	for _, c := range p.Cells {
+		y := ((top+c[1])%height + height) % height
+		x := ((left+c[0])%width + width) % width
+		board[y][x].Player = player
+	}
+}
+
+// FromBoard makes a pattern of a board's live cells. The board's size is
+// kept, so empty space around them isn't lost.
+func FromBoard(board [][]life.Cell) *Pattern {
+	p := &Pattern{Width: len(board[0]), Height: len(board)}
+	for y, row := range board {
+		for x, cell := range row {
+			if cell.Player != life.DeadPlayer {
+				p.Cells = append(p.Cells, [2]int{x, y})
+			}
+		}
+	}
+	return p
+}
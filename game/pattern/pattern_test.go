@@ -0,0 +1,101 @@
+package pattern
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var glider = &Pattern{
+	Name:     "Glider",
+	Comments: []string{"The smallest spaceship"},
+	Width:    3,
+	Height:   3,
+	Cells:    [][2]int{{1, 0}, {2, 1}, {0, 2}, {1, 2}, {2, 2}},
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		format string
+		text   string
+	}{
+		{"rle", "#N Glider\n#C The smallest spaceship\nx = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n"},
+		{"cells", "!Name: Glider\n!The smallest spaceship\n.O.\n..O\nOOO\n\n"},
+		{"life106", "#Life 1.06\n#N Glider\n#C The smallest spaceship\n0 -1\n1 0\n-1 1\n0 1\n1 1\n"},
+	}
+
+	for _, tt := range tests {
+		p, err := Read(strings.NewReader(tt.text), tt.format)
+		if err != nil {
+			t.Errorf("%v: %v", tt.format, err)
+			continue
+		}
+		// Only RLE says what the rule is
+		p.Rule = ""
+		if !reflect.DeepEqual(p, glider) {
+			t.Errorf("%v: got %+v, want %+v", tt.format, p, glider)
+		}
+	}
+}
+
+func TestReadRejectsBadSizes(t *testing.T) {
+	tests := []struct {
+		format string
+		text   string
+	}{
+		{"rle", "x = -3, y = 1\nb!\n"},
+		{"rle", "x = 100000000, y = 100000000\n!\n"},
+		{"rle", "x = 1, y = 1\n99999999999o!\n"},
+		{"rle", "x = 1, y = 1\n2000o2000o!\n"},
+		{"life106", "#Life 1.06\n0 0\n100000000 0\n"},
+		{"life106", "#Life 1.06\n-9223372036854775808 0\n9223372036854775807 0\n"},
+		{"cells", strings.Repeat(".", MaxSize) + "O\n"},
+	}
+
+	for _, tt := range tests {
+		if p, err := Read(strings.NewReader(tt.text), tt.format); err == nil {
+			t.Errorf("%v: read %q as %vx%v", tt.format, tt.text, p.Width, p.Height)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	// Runs longer than 9, an empty row and trailing space
+	p := &Pattern{
+		Name:   "Line",
+		Width:  14,
+		Height: 4,
+		Cells:  [][2]int{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}, {6, 0}, {7, 0}, {8, 0}, {9, 0}, {10, 0}, {0, 2}},
+	}
+
+	for _, format := range Formats {
+		sb := strings.Builder{}
+		if err := Write(&sb, p, format); err != nil {
+			t.Fatalf("%v: %v", format, err)
+		}
+		got, err := Read(strings.NewReader(sb.String()), format)
+		if err != nil {
+			t.Errorf("%v: %v\n%v", format, err, sb.String())
+			continue
+		}
+		got.Rule = ""
+
+		want := *p
+		// Only RLE keeps the empty space around cells
+		if format != "rle" {
+			want.Width, want.Height = 11, 3
+		}
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("%v: got %+v, want %+v\n%v", format, *got, want, sb.String())
+		}
+	}
+}
+
+func TestWriteRLE(t *testing.T) {
+	sb := strings.Builder{}
+	Write(&sb, glider, "rle")
+	want := "#N Glider\n#C The smallest spaceship\nx = 3, y = 3, rule = B3/S23\nbo$2bo$3o!\n"
+	if sb.String() != want {
+		t.Errorf("got %q, want %q", sb.String(), want)
+	}
+}
@@ -0,0 +1,209 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/zhengkyl/gol/game/life"
+)
+
+// look is how a character is colored. Colors are palette strings, and empty
+// for the terminal's default.
+type look struct {
+	fg   lipgloss.Color
+	bg   lipgloss.Color
+	bold bool
+}
+
+const resetSequence = termenv.CSI + termenv.ResetSeq + "m"
+
+// transition is a change from one look to another
+type transition struct {
+	from, to look
+	// Whether from is unknown, like at the start of a line
+	reset bool
+}
+
+// sequence returns the escape sequence changing between looks. Only what
+// differs is sent, since most neighboring cells share a color.
+func (t *Theme) sequence(tr transition) string {
+	if seq, ok := t.sequences[tr]; ok {
+		return seq
+	}
+
+	profile := t.Renderer.ColorProfile()
+	seq := ""
+	if profile != termenv.Ascii {
+		var codes []string
+		from, to := tr.from, tr.to
+		if tr.reset {
+			codes = append(codes, termenv.ResetSeq)
+			from = look{}
+		}
+
+		if to.fg != from.fg {
+			if c := profile.Color(string(to.fg)); c != nil {
+				codes = append(codes, c.Sequence(false))
+			} else {
+				codes = append(codes, "39")
+			}
+		}
+		if to.bg != from.bg {
+			if c := profile.Color(string(to.bg)); c != nil {
+				codes = append(codes, c.Sequence(true))
+			} else {
+				codes = append(codes, "49")
+			}
+		}
+		if to.bold && !from.bold {
+			codes = append(codes, termenv.BoldSeq)
+		} else if !to.bold && from.bold {
+			codes = append(codes, "22")
+		}
+
+		if len(codes) > 0 {
+			seq = termenv.CSI + strings.Join(codes, ";") + "m"
+		}
+	}
+
+	if t.sequences == nil {
+		t.sequences = make(map[transition]string)
+	}
+	t.sequences[tr] = seq
+	return seq
+}
+
+// rowWriter writes a line of characters, only switching looks when they
+// change. Styling every character separately costs several times the bytes.
+type rowWriter struct {
+	t       *Theme
+	sb      *strings.Builder
+	current look
+	started bool
+}
+
+func (w *rowWriter) write(lk look, s string) {
+	if !w.started || lk != w.current {
+		w.sb.WriteString(w.t.sequence(transition{w.current, lk, !w.started}))
+		w.current = lk
+		w.started = true
+	}
+	w.sb.WriteString(s)
+}
+
+// end resets the look, so it doesn't bleed into whatever is drawn next
+func (w *rowWriter) end() {
+	if w.started && w.t.Renderer.ColorProfile() != termenv.Ascii {
+		w.sb.WriteString(resetSequence)
+	}
+	w.started = false
+	w.current = look{}
+}
+
+// cellRender is a cell ready to be written
+type cellRender struct {
+	look look
+	text string
+}
+
+// renderKey is what a board render depends on besides the board
+type renderKey struct {
+	palette int
+	glyphs  bool
+}
+
+// boardRender is every cell of the board, without cursors or pings. It's
+// shared by every session with the same palette and glyph setting, and
+// redrawn when the board changes.
+type boardRender struct {
+	version int
+	cells   [][]cellRender
+}
+
+// changed invalidates cached renders. It must be called with boardMutex held.
+func (l *Lobby) changed() {
+	l.version++
+}
+
+// cachedRender returns the board drawn for a theme. It must be called with
+// playersMutex and boardMutex held for reading.
+func (l *Lobby) cachedRender(t *Theme) [][]cellRender {
+	key := renderKey{t.Palette, t.ShowGlyphs()}
+
+	l.renderMutex.Lock()
+	defer l.renderMutex.Unlock()
+
+	if r, ok := l.renders[key]; ok && r.version == l.version {
+		return r.cells
+	}
+
+	cells := make([][]cellRender, len(l.board))
+	for y, row := range l.board {
+		cells[y] = make([]cellRender, len(row))
+		for x, cell := range row {
+			cells[y][x] = l.renderCell(t, cell, 0, 0)
+		}
+	}
+
+	if l.renders == nil {
+		l.renders = make(map[renderKey]*boardRender)
+	}
+	l.renders[key] = &boardRender{l.version, cells}
+	return cells
+}
+
+// renderCell draws a cell at normal zoom, with the color of a cursor or ping
+// on it, or 0 if there isn't one. It must be called with playersMutex held.
+func (l *Lobby) renderCell(t *Theme, cell life.Cell, cursor, ping int) cellRender {
+	if cell.Player == life.DeadPlayer && cell.PausedPlayer == life.DeadPlayer && cursor == 0 && ping == 0 {
+		return cellRender{look{bg: lipgloss.Color(t.color(0).Cell)}, "  "}
+	}
+
+	var lk look
+	pixel := "  "
+
+	if cursor != 0 {
+		pixel = "[]"
+		lk.fg = lipgloss.Color(t.color(cursor).Cursor)
+	} else if ping != 0 {
+		pixel = "<>"
+		lk.fg = lipgloss.Color(t.color(ping).Cursor)
+		lk.bold = true
+	}
+
+	if cell.Player != life.DeadPlayer {
+		color := -1
+		player, ok := l.players[cell.Player]
+		if ok {
+			color = player.Color
+		} else if cell.Player == life.NeutralPlayer {
+			color = NeutralColor
+		}
+
+		if color >= 0 {
+			lk.bg = lipgloss.Color(t.color(color).Cell)
+			if t.ShowGlyphs() && cursor == 0 && ping == 0 {
+				lk.fg = lipgloss.Color(t.color(0).Cursor)
+				pixel = glyph(color)
+			}
+		}
+	}
+	// Pings stay visible over paused cells
+	if cell.PausedPlayer != life.DeadPlayer && ping == 0 {
+		player, ok := l.players[cell.PausedPlayer]
+		if ok {
+			if cursor == 0 {
+				lk.fg = lipgloss.Color(t.color(player.Color).Cell)
+				pixel = "::"
+				if t.ShowGlyphs() {
+					pixel = Glyphs[player.Color][:1] + ":"
+				}
+			} else {
+				pixel = ":]"
+			}
+		}
+	}
+
+	return cellRender{lk, pixel}
+}
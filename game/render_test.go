@@ -0,0 +1,84 @@
+package game
+
+import (
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func benchLobby() *Lobby {
+	l := newLobby("bench", DefaultSettings)
+	for id := 1; id <= 4; id++ {
+		l.Join(id, "bench", nil)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for y := range l.board {
+		for x := range l.board[y] {
+			if r.Intn(4) == 0 {
+				l.board[y][x].Player = 1 + r.Intn(4)
+			}
+		}
+	}
+	return l
+}
+
+// changedBytes is what the bubbletea renderer sends after a frame, since it
+// skips lines that haven't changed
+func changedBytes(prev, frame string) int {
+	prevLines := strings.Split(prev, "\n")
+	n := 0
+	for i, line := range strings.Split(frame, "\n") {
+		if i >= len(prevLines) || line != prevLines[i] {
+			n += len(line)
+		}
+	}
+	return n
+}
+
+// BenchmarkViewBoard draws a 240x45 terminal worth of board, with a new
+// generation every frame
+func BenchmarkViewBoard(b *testing.B) {
+	l := benchLobby()
+	r := lipgloss.NewRenderer(io.Discard)
+	r.SetColorProfile(termenv.ANSI256)
+	theme := &Theme{Renderer: r}
+
+	prev := ""
+	total, changed := 0, 0
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		frame := l.ViewBoard(theme, ZoomNormal, false, 0, 0, 120, 45)
+
+		total += len(frame)
+		changed += changedBytes(prev, frame)
+		prev = frame
+
+		b.StopTimer()
+		l.UpdateBoard()
+		b.StartTimer()
+	}
+
+	b.ReportMetric(float64(total)/float64(b.N), "bytes/frame")
+	b.ReportMetric(float64(changed)/float64(b.N), "sent-bytes/frame")
+}
+
+func TestNoOpPaintKeepsCache(t *testing.T) {
+	l := newLobby("paint", DefaultSettings)
+	l.Join(1, "painter", nil)
+
+	l.Paint(1, true)
+	version := l.version
+	// Already placed, then removing a cell that isn't there
+	l.Paint(1, true)
+	l.MoveCursor(1, l.players[1].PosX+1, l.players[1].PosY)
+	l.Paint(1, false)
+	if l.version != version {
+		t.Error("painting without changing anything redrew the board")
+	}
+}
@@ -0,0 +1,131 @@
+package game
+
+import "fmt"
+
+// Speeds are the generations per second a lobby can run at
+var Speeds = []int{1, 2, 5, 10, 20, 40}
+
+// DefaultSpeed is the index of generationRate in Speeds
+const DefaultSpeed = 2
+
+// SpeedLabel describes a speed for status bars
+func SpeedLabel(speed int, frozen bool) string {
+	if frozen {
+		return "frozen"
+	}
+	return fmt.Sprintf("%d gen/s", Speeds[speed])
+}
+
+// due returns how many generations to run this tick
+func (l *Lobby) due() int {
+	l.boardMutex.Lock()
+	defer l.boardMutex.Unlock()
+
+	if l.frozen {
+		return 0
+	}
+	l.progress += Speeds[l.speed]
+	n := l.progress / drawRate
+	l.progress %= drawRate
+	return n
+}
+
+// Generation counts generations since the lobby started
+func (l *Lobby) Generation() int {
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+	return l.generation
+}
+
+// RoundLeft counts the generations until the round ends
+func (l *Lobby) RoundLeft() int {
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+	return roundLength - l.generation%roundLength
+}
+
+// Speed returns the index into Speeds and whether generations are stopped
+func (l *Lobby) Speed() (int, bool) {
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+	return l.speed, l.frozen
+}
+
+// Host is the player who has been in the lobby longest, and the only one
+// who can change its speed
+func (l *Lobby) Host() int {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+
+	var host *PlayerState
+	for _, p := range l.players {
+		if host == nil || hostsBefore(p, host) {
+			host = p
+		}
+	}
+	if host == nil {
+		return 0
+	}
+	return host.Id
+}
+
+// hostsBefore prefers people, then connected players, then whoever joined
+// first
+func hostsBefore(a, b *PlayerState) bool {
+	if a.Bot != b.Bot {
+		return !a.Bot
+	}
+	if a.Disconnected != b.Disconnected {
+		return !a.Disconnected
+	}
+	if !a.Joined.Equal(b.Joined) {
+		return a.Joined.Before(b.Joined)
+	}
+	return a.Id < b.Id
+}
+
+// ChangeSpeed moves delta steps through Speeds
+func (l *Lobby) ChangeSpeed(id, delta int) {
+	if l.Host() != id {
+		return
+	}
+
+	l.boardMutex.Lock()
+	defer l.boardMutex.Unlock()
+
+	l.speed += delta
+	if l.speed < 0 {
+		l.speed = 0
+	}
+	if l.speed >= len(Speeds) {
+		l.speed = len(Speeds) - 1
+	}
+}
+
+// ToggleFrozen stops or restarts generations
+func (l *Lobby) ToggleFrozen(id int) {
+	if l.Host() != id {
+		return
+	}
+
+	l.boardMutex.Lock()
+	defer l.boardMutex.Unlock()
+
+	l.frozen = !l.frozen
+	l.progress = 0
+}
+
+// Step runs a single generation while frozen
+func (l *Lobby) Step(id int) {
+	if l.Host() != id {
+		return
+	}
+
+	l.updateMutex.Lock()
+	defer l.updateMutex.Unlock()
+
+	_, frozen := l.Speed()
+	if frozen {
+		l.UpdateBoard()
+	}
+}
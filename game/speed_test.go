@@ -0,0 +1,51 @@
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDue(t *testing.T) {
+	l := newLobby("test", DefaultSettings)
+
+	for i, speed := range Speeds {
+		l.speed = i
+		l.progress = 0
+
+		total := 0
+		for tick := 0; tick < drawRate; tick++ {
+			total += l.due()
+		}
+		if total != speed {
+			t.Errorf("speed %v ran %v generations in a second", speed, total)
+		}
+	}
+
+	l.frozen = true
+	if n := l.due(); n != 0 {
+		t.Errorf("frozen lobby ran %v generations", n)
+	}
+}
+
+func TestPingsWhileFrozen(t *testing.T) {
+	l := newLobby("test", DefaultSettings)
+	l.frozen = true
+	if _, err := l.Join(1, "a", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 100; i++ {
+		l.Ping(1)
+	}
+	if n := len(l.pings); n != 1 {
+		t.Errorf("got %v pings, want 1 while cooling down", n)
+	}
+
+	// Expired pings go without a generation running
+	l.pings[0].expires = time.Now().Add(-time.Second)
+	l.players[1].lastPing = time.Time{}
+	l.Ping(1)
+	if n := len(l.pings); n != 1 {
+		t.Errorf("got %v pings, want the expired one gone", n)
+	}
+}
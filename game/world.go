@@ -0,0 +1,307 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	petname "github.com/dustinkirkland/golang-petname"
+	"github.com/zhengkyl/gol/game/life"
+)
+
+const worldSaveInterval = time.Minute
+
+// Worlds nobody plays in for this long are deleted, so abandoned ones don't
+// use up MaxWorlds forever
+const worldExpiry = 30 * 24 * time.Hour
+
+// worldSnapshot is what a persistent lobby looks like on disk. Player ids
+// don't survive restarts, so every live cell is saved as neutral.
+type worldSnapshot struct {
+	Name       string   `json:"name"`
+	Width      int      `json:"width"`
+	Height     int      `json:"height"`
+	Generation int      `json:"generation"`
+	Cells      [][2]int `json:"cells"`
+	// When someone was last in the world
+	LastPlayed time.Time `json:"last_played"`
+	// Generations per second, from Speeds
+	Speed          int  `json:"speed"`
+	Frozen         bool `json:"frozen"`
+	MaxPlayers     int  `json:"max_players"`
+	MaxPlacedCells int  `json:"max_placed_cells"`
+}
+
+func (l *Lobby) snapshot() worldSnapshot {
+	lastPlayed := l.played()
+
+	l.boardMutex.RLock()
+	defer l.boardMutex.RUnlock()
+
+	ws := worldSnapshot{
+		Name:           l.name,
+		Width:          len(l.board[0]),
+		Height:         len(l.board),
+		Generation:     l.generation,
+		LastPlayed:     lastPlayed,
+		Speed:          Speeds[l.speed],
+		Frozen:         l.frozen,
+		MaxPlayers:     l.settings.MaxPlayers,
+		MaxPlacedCells: l.settings.MaxPlacedCells,
+	}
+	for y, row := range l.board {
+		for x, cell := range row {
+			if cell.Player != life.DeadPlayer {
+				ws.Cells = append(ws.Cells, [2]int{x, y})
+			}
+		}
+	}
+	return ws
+}
+
+// played returns when someone was last in the lobby, which is now if anyone
+// still is
+func (l *Lobby) played() time.Time {
+	l.playersMutex.RLock()
+	defer l.playersMutex.RUnlock()
+
+	for _, p := range l.players {
+		if !p.Bot {
+			return time.Now()
+		}
+	}
+	return l.lastPlayed
+}
+
+// restoreWorld keeps the world's own size, speed and limits, but otherwise
+// follows settings
+func restoreWorld(ws worldSnapshot, settings Settings) (*Lobby, error) {
+	if ws.Width <= 0 || ws.Height <= 0 {
+		return nil, fmt.Errorf("world %v has invalid size %vx%v", ws.Name, ws.Width, ws.Height)
+	}
+
+	settings.Width = ws.Width
+	settings.Height = ws.Height
+	// Worlds saved before these were kept follow the server's
+	if ws.MaxPlayers > 0 && ws.MaxPlayers <= MaxPlayers {
+		settings.MaxPlayers = ws.MaxPlayers
+	}
+	if ws.MaxPlacedCells > 0 {
+		settings.MaxPlacedCells = ws.MaxPlacedCells
+	}
+	l := newLobby(ws.Name, settings)
+	for i, speed := range Speeds {
+		if speed == ws.Speed {
+			l.speed = i
+		}
+	}
+	l.frozen = ws.Frozen
+	l.persistent = true
+	l.generation = ws.Generation
+	l.lastPlayed = ws.LastPlayed
+	// Saved before worlds expired
+	if l.lastPlayed.IsZero() {
+		l.lastPlayed = time.Now()
+	}
+
+	for _, c := range ws.Cells {
+		x, y := c[0], c[1]
+		if x < 0 || x >= ws.Width || y < 0 || y >= ws.Height {
+			return nil, fmt.Errorf("world %v has cell (%v, %v) out of bounds", ws.Name, x, y)
+		}
+		l.board[y][x].Player = life.NeutralPlayer
+	}
+	return l, nil
+}
+
+func worldPath(dir, name string) string {
+	return filepath.Join(dir, name+".json")
+}
+
+// LoadWorlds restores every world saved in dir and keeps saving them there
+func (gm *Manager) LoadWorlds(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	gm.worldsDir = dir
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		bytes, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+
+		var ws worldSnapshot
+		if err := json.Unmarshal(bytes, &ws); err != nil {
+			return fmt.Errorf("world %v is corrupted: %w", entry.Name(), err)
+		}
+		// Worlds are saved by name, so a name from inside the file could put
+		// saves anywhere
+		ws.Name = strings.TrimSuffix(entry.Name(), ".json")
+
+		l, err := restoreWorld(ws, gm.settings)
+		if err != nil {
+			return err
+		}
+		gm.addLobby(l)
+		log.Info("Restored world", "name", ws.Name, "generation", ws.Generation)
+	}
+
+	stop := make(chan struct{})
+	gm.saveMutex.Lock()
+	gm.stopSaving = stop
+	gm.saveMutex.Unlock()
+	go func() {
+		ticker := time.NewTicker(worldSaveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			if err := gm.SaveWorlds(); err != nil {
+				log.Error("could not save worlds", "err", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// SaveWorlds writes every persistent lobby to disk
+func (gm *Manager) SaveWorlds() error {
+	if gm.worldsDir == "" {
+		return nil
+	}
+
+	gm.saveMutex.Lock()
+	defer gm.saveMutex.Unlock()
+
+	var worlds []*Lobby
+	gm.lobbiesMutex.RLock()
+	for _, l := range gm.lobbies {
+		if l.persistent {
+			worlds = append(worlds, l)
+		}
+	}
+	gm.lobbiesMutex.RUnlock()
+
+	for _, l := range worlds {
+		ws := l.snapshot()
+		if time.Since(ws.LastPlayed) > worldExpiry {
+			gm.deleteLobby(l.id)
+			if err := os.Remove(worldPath(gm.worldsDir, l.name)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			log.Info("Deleted unplayed world", "name", l.name, "last played", ws.LastPlayed)
+			continue
+		}
+
+		bytes, err := json.Marshal(ws)
+		if err != nil {
+			return err
+		}
+
+		if err := writeFile(worldPath(gm.worldsDir, l.name), bytes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile writes to a temporary file then renames it, so a crash never
+// leaves a half written file
+func writeFile(path string, bytes []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(bytes)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Close stops saving worlds periodically, and saves them one last time
+func (gm *Manager) Close() error {
+	gm.saveMutex.Lock()
+	if gm.stopSaving != nil {
+		close(gm.stopSaving)
+		gm.stopSaving = nil
+	}
+	gm.saveMutex.Unlock()
+	return gm.SaveWorlds()
+}
+
+func (gm *Manager) checkWorldLimit() error {
+	gm.lobbiesMutex.RLock()
+	defer gm.lobbiesMutex.RUnlock()
+
+	worlds := 0
+	for _, l := range gm.lobbies {
+		if l.persistent {
+			worlds++
+		}
+	}
+	if worlds >= gm.settings.MaxWorlds {
+		return fmt.Errorf("Server has reached capacity of %v worlds", gm.settings.MaxWorlds)
+	}
+	return nil
+}
+
+// CreateWorld creates a persistent lobby, which is only possible once worlds
+// have been loaded. Guests can't, since worlds outlast them.
+func (gm *Manager) CreateWorld(playerId int) (int, error) {
+	if gm.worldsDir == "" {
+		return 0, fmt.Errorf("persistent worlds are disabled")
+	}
+
+	gm.playersMutex.RLock()
+	key := gm.players[playerId].key
+	gm.playersMutex.RUnlock()
+	if key == "" {
+		return 0, fmt.Errorf("Connect with an ssh key to create worlds")
+	}
+
+	if err := gm.checkWorldLimit(); err != nil {
+		return 0, err
+	}
+
+	name := petname.Generate(2, "-")
+	for {
+		if _, err := os.Stat(worldPath(gm.worldsDir, name)); os.IsNotExist(err) {
+			break
+		}
+		name = petname.Generate(2, "-")
+	}
+
+	l := newLobby(name, gm.settings)
+	l.persistent = true
+	id := gm.addLobby(l)
+
+	return id, gm.SaveWorlds()
+}
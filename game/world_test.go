@@ -0,0 +1,124 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zhengkyl/gol/game/life"
+)
+
+func TestWorldsSurviveRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	gm := NewManager(nil, DefaultSettings)
+	if err := gm.LoadWorlds(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gm.CreateWorld(gm.Connect(NewClient(), "", "guest")); err == nil {
+		t.Error("a guest created a world")
+	}
+	id, err := gm.CreateWorld(gm.Connect(NewClient(), "key:a", "player"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := gm.lobbies[id]
+	l.boardMutex.Lock()
+	l.board[1][2].Player = 5
+	l.generation = 42
+	l.speed = 4
+	l.frozen = true
+	l.settings.MaxPlacedCells = 7
+	l.boardMutex.Unlock()
+
+	// Saves can overlap, like the ticker's and shutdown's
+	errs := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			errs <- gm.SaveWorlds()
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gm.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewManager(nil, DefaultSettings)
+	if err := restarted.LoadWorlds(dir); err != nil {
+		t.Fatal(err)
+	}
+	infos := restarted.LobbyInfos()
+	if len(infos) != 1 || infos[0].Name != l.name || !infos[0].Persistent {
+		t.Fatalf("got %+v, want world %v", infos, l.name)
+	}
+
+	restored := restarted.lobbies[infos[0].Id]
+	if speed, frozen := restored.Speed(); speed != 4 || !frozen {
+		t.Errorf("got speed %v and frozen %v, want 4 and frozen", speed, frozen)
+	}
+	if got := restored.Settings().MaxPlacedCells; got != 7 {
+		t.Errorf("got %v placed cells allowed, want 7", got)
+	}
+	restored.boardMutex.RLock()
+	defer restored.boardMutex.RUnlock()
+	if restored.board[1][2].Player != life.NeutralPlayer {
+		t.Errorf("got cell owned by %v, want neutral", restored.board[1][2].Player)
+	}
+}
+
+func TestUnplayedWorldsExpire(t *testing.T) {
+	dir := t.TempDir()
+
+	gm := NewManager(nil, DefaultSettings)
+	if err := gm.LoadWorlds(dir); err != nil {
+		t.Fatal(err)
+	}
+	id, err := gm.CreateWorld(gm.Connect(NewClient(), "key:a", "player"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := gm.lobbies[id]
+	l.playersMutex.Lock()
+	l.lastPlayed = time.Now().Add(-worldExpiry - time.Hour)
+	l.playersMutex.Unlock()
+
+	if err := gm.SaveWorlds(); err != nil {
+		t.Fatal(err)
+	}
+	if infos := gm.LobbyInfos(); len(infos) != 0 {
+		t.Errorf("got %+v, want the world deleted", infos)
+	}
+	if _, err := os.Stat(worldPath(dir, l.name)); !os.IsNotExist(err) {
+		t.Errorf("world file wasn't removed: %v", err)
+	}
+}
+
+func TestWorldsSaveUnderTheirFileName(t *testing.T) {
+	dir := t.TempDir()
+	world := `{"name":"../escaped","width":4,"height":4,"generation":1}`
+	if err := os.WriteFile(filepath.Join(dir, "kept.json"), []byte(world), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	gm := NewManager(nil, DefaultSettings)
+	if err := gm.LoadWorlds(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := gm.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "..", "escaped.json")); !os.IsNotExist(err) {
+		t.Errorf("saved outside the worlds dir: %v", err)
+	}
+	if infos := gm.LobbyInfos(); len(infos) != 1 || infos[0].Name != "kept" {
+		t.Errorf("got %+v, want world kept", infos)
+	}
+}
@@ -0,0 +1,207 @@
+package game
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Zoom is how many cells fit in a terminal character
+type Zoom int
+
+const (
+	// ZoomNormal draws each cell 2 columns wide
+	ZoomNormal Zoom = iota
+	// ZoomHalf draws 2 cells per character, one above the other
+	ZoomHalf
+	// ZoomBraille draws 8 cells per character, 2 across and 4 down
+	ZoomBraille
+	numZooms
+)
+
+func (z Zoom) String() string {
+	switch z {
+	case ZoomHalf:
+		return "half-block"
+	case ZoomBraille:
+		return "braille"
+	}
+	return "normal"
+}
+
+// Next returns the next zoom level, wrapping back to normal
+func (z Zoom) Next() Zoom {
+	return (z + 1) % numZooms
+}
+
+// Cells returns how many cells fit in a terminal area
+func (z Zoom) Cells(cols, rows int) (int, int) {
+	switch z {
+	case ZoomHalf:
+		return cols, rows * 2
+	case ZoomBraille:
+		return cols * 2, rows * 4
+	}
+	return cols / 2, rows
+}
+
+// Cell returns the cell under a terminal character. Characters holding
+// several cells return the top left one.
+func (z Zoom) Cell(col, row int) (int, int) {
+	switch z {
+	case ZoomHalf:
+		return col, row * 2
+	case ZoomBraille:
+		return col * 2, row * 4
+	}
+	return col / 2, row
+}
+
+// Pixel is what a zoomed out view needs to know about a cell
+type Pixel struct {
+	// Color of the cell, or empty if it's dead
+	Color lipgloss.Color
+	// Color of a cursor or ping on the cell, or empty if there isn't one
+	Cursor lipgloss.Color
+}
+
+// brailleDots[y][x] is the bit for a dot in a braille character
+var brailleDots = [4][2]rune{
+	{0x01, 0x08},
+	{0x02, 0x10},
+	{0x04, 0x20},
+	{0x40, 0x80},
+}
+
+const brailleBlank = 0x2800
+
+// RenderZoomed draws width x height cells at a zoom level other than normal.
+// at returns the cell at a position relative to the top left corner.
+func RenderZoomed(t *Theme, z Zoom, width, height int, at func(x, y int) Pixel) string {
+	dead := lipgloss.Color(t.color(0).Cell)
+	shapes := t.ShowGlyphs()
+
+	get := func(x, y int) Pixel {
+		if x >= width || y >= height {
+			return Pixel{}
+		}
+		return at(x, y)
+	}
+
+	sb := strings.Builder{}
+	w := rowWriter{t: t, sb: &sb}
+
+	switch z {
+	case ZoomHalf:
+		for y := 0; y < height; y += 2 {
+			for x := 0; x < width; x++ {
+				lk, char := renderHalf(dead, shapes, get(x, y), get(x, y+1))
+				w.write(lk, char)
+			}
+			w.end()
+			if y+2 < height {
+				sb.WriteString("\n")
+			}
+		}
+
+	case ZoomBraille:
+		for y := 0; y < height; y += 4 {
+			for x := 0; x < width; x += 2 {
+				var pixels [4][2]Pixel
+				for dy := 0; dy < 4; dy++ {
+					for dx := 0; dx < 2; dx++ {
+						pixels[dy][dx] = get(x+dx, y+dy)
+					}
+				}
+				lk, char := renderBraille(dead, pixels)
+				w.write(lk, char)
+			}
+			w.end()
+			if y+4 < height {
+				sb.WriteString("\n")
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// renderHalf draws two cells as the top and bottom halves of a character.
+// Shapes are used instead of background colors when colors might be missing.
+func renderHalf(dead lipgloss.Color, shapes bool, top, bottom Pixel) (look, string) {
+	// Cursors point at their cell from the other half
+	if top.Cursor != "" || bottom.Cursor != "" {
+		char, cursor, other := "▲", top.Cursor, bottom.Color
+		if cursor == "" {
+			char, cursor, other = "▼", bottom.Cursor, top.Color
+		}
+		if other == "" {
+			other = dead
+		}
+		return look{fg: cursor, bg: other, bold: true}, char
+	}
+
+	if shapes {
+		char, color := " ", top.Color
+		switch {
+		case top.Color != "" && bottom.Color != "":
+			char = "█"
+		case top.Color != "":
+			char = "▀"
+		case bottom.Color != "":
+			char, color = "▄", bottom.Color
+		}
+		return look{fg: color, bg: dead}, char
+	}
+
+	topColor, bottomColor := top.Color, bottom.Color
+	if topColor == "" {
+		topColor = dead
+	}
+	if bottomColor == "" {
+		bottomColor = dead
+	}
+	if topColor == bottomColor {
+		return look{bg: topColor}, " "
+	}
+	return look{fg: topColor, bg: bottomColor}, "▀"
+}
+
+// renderBraille draws 8 cells as the dots of a character, colored by
+// whichever color most of them have
+func renderBraille(dead lipgloss.Color, pixels [4][2]Pixel) (look, string) {
+	char := rune(brailleBlank)
+
+	// A map would be simpler, but this runs for every character
+	var colors [8]lipgloss.Color
+	var counts [8]int
+	dominant := -1
+
+	for dy, row := range pixels {
+		for dx, p := range row {
+			if p.Cursor != "" {
+				return look{fg: p.Cursor, bg: dead, bold: true}, "◆"
+			}
+			if p.Color == "" {
+				continue
+			}
+			char |= brailleDots[dy][dx]
+
+			i := 0
+			for counts[i] > 0 && colors[i] != p.Color {
+				i++
+			}
+			colors[i] = p.Color
+			counts[i]++
+			if dominant < 0 || counts[i] > counts[dominant] {
+				dominant = i
+			}
+		}
+	}
+
+	lk := look{bg: dead}
+	if dominant >= 0 {
+		lk.fg = colors[dominant]
+	}
+	return lk, string(char)
+}
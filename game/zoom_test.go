@@ -0,0 +1,41 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestRenderZoomed(t *testing.T) {
+	r := lipgloss.NewRenderer(nil)
+	r.SetColorProfile(termenv.Ascii)
+	theme := &Theme{Renderer: r}
+
+	// A column of live cells and a cursor in the corner
+	alive := map[[2]int]bool{{0, 0}: true, {0, 1}: true, {1, 0}: true, {2, 1}: true}
+	at := func(x, y int) Pixel {
+		var p Pixel
+		if alive[[2]int{x, y}] {
+			p.Color = lipgloss.Color("1")
+		}
+		if x == 3 && y == 2 {
+			p.Cursor = lipgloss.Color("2")
+		}
+		return p
+	}
+
+	tests := []struct {
+		zoom Zoom
+		want string
+	}{
+		{ZoomHalf, "█▀▄ \n   ▲"},
+		{ZoomBraille, "⠋◆"},
+	}
+	for _, tt := range tests {
+		got := RenderZoomed(theme, tt.zoom, 4, 3, at)
+		if got != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.zoom, got, tt.want)
+		}
+	}
+}
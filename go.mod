@@ -2,7 +2,10 @@ module github.com/zhengkyl/gol
 
 go 1.19
 
-require github.com/charmbracelet/wish v1.1.0
+require (
+	github.com/BurntSushi/toml v1.2.1
+	github.com/charmbracelet/wish v1.1.0
+)
 
 require (
 	github.com/containerd/console v1.0.4-0.20230313162750-1ae8d489ac81 // indirect
@@ -34,7 +37,7 @@ require (
 	github.com/muesli/termenv v0.15.1
 	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/zhengkyl/pearls v0.1.0
-	golang.org/x/crypto v0.7.0 // indirect
+	golang.org/x/crypto v0.7.0
 	golang.org/x/exp v0.0.0-20230801115018-d63ba01acd4b
 	golang.org/x/sys v0.6.0 // indirect
 )
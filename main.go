@@ -1,6 +1,16 @@
 package main
 
-import "github.com/zhengkyl/gol/server"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zhengkyl/gol/cli"
+	"github.com/zhengkyl/gol/config"
+	"github.com/zhengkyl/gol/server"
+)
 
 // _ "net/http/pprof"
 
@@ -8,5 +18,37 @@ func main() {
 	// go func() {
 	// 	http.ListenAndServe("localhost:1234", nil)
 	// }()
-	server.RunServer()
+	err := run(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run picks a subcommand. Without one, the server starts, like it always has.
+func run(args []string) error {
+	cmd := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "serve":
+		cfg, err := config.Load(args)
+		if err != nil {
+			return err
+		}
+		server.RunServer(cfg)
+		return nil
+	case "run":
+		return cli.Run(args, os.Stdin, os.Stdout)
+	case "convert":
+		return cli.Convert(args, os.Stdin, os.Stdout)
+	case "local":
+		return cli.Local(args)
+	}
+	return fmt.Errorf("unknown command %q, use serve, local, run or convert", cmd)
 }
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestIdentifyOnlyTrustsSignedKeys(t *testing.T) {
+	srv, err := wish.NewServer(
+		wish.WithHostKeyPath(filepath.Join(t.TempDir(), "host")),
+		withVerifiedKeys(),
+		wish.WithKeyboardInteractiveAuth(func(ctx ssh.Context, challenger gossh.KeyboardInteractiveChallenge) bool {
+			return true
+		}),
+		func(srv *ssh.Server) error {
+			srv.Handler = func(s ssh.Session) {
+				key, _ := identify(s)
+				io.WriteString(s, key)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	identity := func(auth ...gossh.AuthMethod) string {
+		client, err := gossh.Dial("tcp", ln.Addr().String(), &gossh.ClientConfig{
+			User:            "someone",
+			Auth:            auth,
+			HostKeyCallback: gossh.InsecureIgnoreHostKey(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		session, err := client.NewSession()
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, _ := session.Output("")
+		return string(out)
+	}
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := gossh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "key:" + gossh.FingerprintSHA256(signer.PublicKey())
+	if got := identity(gossh.PublicKeys(signer)); got != want {
+		t.Errorf("got %q for a signed key, want %q", got, want)
+	}
+
+	noAnswers := gossh.KeyboardInteractive(func(user, instruction string, questions []string, echos []bool) ([]string, error) {
+		return nil, nil
+	})
+	if got := identity(noAnswers); got != "" {
+		t.Errorf("got %q for keyboard-interactive, want a guest", got)
+	}
+
+	// Offered keys can't overwrite each other, since the one kept is
+	// whichever was signed
+	var offered ssh.Server
+	withVerifiedKeys()(&offered)
+	config := offered.ServerConfigCallback(nil)
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := gossh.NewPublicKey(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := config.PublicKeyCallback(nil, signer.PublicKey())
+	config.PublicKeyCallback(nil, otherKey)
+	if got := first.Extensions[keyExtension]; got != gossh.FingerprintSHA256(signer.PublicKey()) {
+		t.Errorf("offering another key changed the first's fingerprint to %v", got)
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/muesli/termenv"
+)
+
+var colorProfiles = map[string]termenv.Profile{
+	"truecolor": termenv.TrueColor,
+	"ansi256":   termenv.ANSI256,
+	"ansi":      termenv.ANSI,
+	"ascii":     termenv.Ascii,
+}
+
+// sessionColorProfile uses the configured profile, unless it's "auto", in
+// which case it's guessed from the client's TERM and COLORTERM.
+func sessionColorProfile(configured string, pty ssh.Pty, environ []string) termenv.Profile {
+	if p, ok := colorProfiles[configured]; ok {
+		return p
+	}
+
+	var colorTerm string
+	for _, kv := range environ {
+		if strings.HasPrefix(kv, "COLORTERM=") {
+			colorTerm = strings.TrimPrefix(kv, "COLORTERM=")
+		}
+	}
+
+	return detectColorProfile(pty.Term, colorTerm)
+}
+
+func detectColorProfile(term, colorTerm string) termenv.Profile {
+	term = strings.ToLower(term)
+	colorTerm = strings.ToLower(colorTerm)
+
+	switch {
+	// Physical terminals like vt100 can't show color
+	case term == "" || term == "dumb" || strings.HasPrefix(term, "vt"):
+		return termenv.Ascii
+	case colorTerm == "truecolor" || colorTerm == "24bit":
+		return termenv.TrueColor
+	case strings.Contains(term, "truecolor") || strings.Contains(term, "direct"):
+		return termenv.TrueColor
+	case strings.Contains(term, "256color"):
+		return termenv.ANSI256
+	}
+	return termenv.ANSI
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestDetectColorProfile(t *testing.T) {
+	tests := []struct {
+		term      string
+		colorTerm string
+		want      termenv.Profile
+	}{
+		{"", "", termenv.Ascii},
+		{"dumb", "truecolor", termenv.Ascii},
+		{"vt100", "", termenv.Ascii},
+		{"xterm", "", termenv.ANSI},
+		{"xterm-256color", "", termenv.ANSI256},
+		{"xterm-256color", "truecolor", termenv.TrueColor},
+		{"xterm-direct", "", termenv.TrueColor},
+	}
+
+	for _, tt := range tests {
+		if got := detectColorProfile(tt.term, tt.colorTerm); got != tt.want {
+			t.Errorf("detectColorProfile(%q, %q) = %v, want %v", tt.term, tt.colorTerm, got, tt.want)
+		}
+	}
+}
@@ -3,9 +3,10 @@ package server
 import (
 	"context"
 	"errors"
-	"fmt"
+	"net"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -16,37 +17,90 @@ import (
 	"github.com/charmbracelet/wish"
 	bm "github.com/charmbracelet/wish/bubbletea"
 	lm "github.com/charmbracelet/wish/logging"
-	"github.com/muesli/termenv"
+	petname "github.com/dustinkirkland/golang-petname"
+	"github.com/zhengkyl/gol/api"
+	"github.com/zhengkyl/gol/config"
 	"github.com/zhengkyl/gol/game"
+	"github.com/zhengkyl/gol/store"
 	"github.com/zhengkyl/gol/ui"
+	gossh "golang.org/x/crypto/ssh"
 )
 
-const (
-	host = "0.0.0.0"
-	port = 2345
-)
+var logFormatters = map[string]log.Formatter{
+	"text":   log.TextFormatter,
+	"json":   log.JSONFormatter,
+	"logfmt": log.LogfmtFormatter,
+}
+
+func RunServer(cfg config.Config) {
 
-func RunServer() {
+	log.SetLevel(log.ParseLevel(cfg.Log.Level))
+	log.SetFormatter(logFormatters[cfg.Log.Format])
 
-	gm := game.NewManager()
+	storePath := filepath.Join(cfg.DataDir, "store.json")
+	st, err := store.Open(storePath)
+	if err != nil {
+		log.Fatal("could not open store", "path", storePath, "err", err)
+	}
+
+	// Already validated
+	difficulty, _ := game.ParseDifficulty(cfg.Bots.Difficulty)
+
+	gm := game.NewManager(st, game.Settings{
+		Width:          cfg.Lobby.Width,
+		Height:         cfg.Lobby.Height,
+		MaxPlayers:     cfg.Limits.MaxPlayers,
+		MaxPlacedCells: cfg.Limits.MaxPlacedCells,
+		MaxLobbies:     cfg.Limits.MaxLobbies,
+		MaxWorlds:      cfg.Limits.MaxWorlds,
+		BotDifficulty:  difficulty,
+		MaxBots:        cfg.Bots.Max,
+	})
+
+	worldsDir := filepath.Join(cfg.DataDir, "worlds")
+	if err := gm.LoadWorlds(worldsDir); err != nil {
+		log.Fatal("could not load worlds", "dir", worldsDir, "err", err)
+	}
+
+	options := []ssh.Option{wish.WithAddress(cfg.Address())}
+	for _, path := range cfg.HostKeyPaths {
+		options = append(options, wish.WithHostKeyPath(path))
+	}
 
-	s, err := wish.NewServer(
-		wish.WithAddress(fmt.Sprintf("%s:%d", host, port)),
-		wish.WithHostKeyPath(".ssh/server_ed25519"),
+	s, err := wish.NewServer(append(options,
+		// Anyone can play, auth is only used to tell players apart
+		withVerifiedKeys(),
+		wish.WithKeyboardInteractiveAuth(func(ctx ssh.Context, challenger gossh.KeyboardInteractiveChallenge) bool {
+			return true
+		}),
 		wish.WithMiddleware(
-			MiddlewareWithProgramHandler(teaHandler(gm), termenv.ANSI256, gm),
+			MiddlewareWithProgramHandler(teaHandler(gm, cfg.ColorProfile), gm),
 			lm.Middleware(),
 		),
-	)
+	)...)
 
 	if err != nil {
 		log.Error("server didn't start", "err", err)
 	}
 
+	var apiListener net.Listener
+	if cfg.API.Address != "" {
+		apiListener, err = api.Listen(cfg.API.Network, cfg.API.Address)
+		if err != nil {
+			log.Fatal("could not start api", "network", cfg.API.Network, "address", cfg.API.Address, "err", err)
+		}
+		log.Info("Starting api", "network", cfg.API.Network, "address", cfg.API.Address)
+		go func() {
+			if err := api.NewServer(gm).Serve(apiListener); err != nil {
+				log.Error("api stopped", "err", err)
+			}
+		}()
+	}
+
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
-	log.Info("Starting SSH server", "host", host, "port", port)
+	log.Info("Starting SSH server", "host", cfg.Host, "port", cfg.Port)
 
 	go func() {
 		if err = s.ListenAndServe(); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
@@ -63,9 +117,20 @@ func RunServer() {
 	if err := s.Shutdown(ctx); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
 		log.Error("could not stop server", "error", err)
 	}
+
+	if apiListener != nil {
+		apiListener.Close()
+	}
+
+	if err := gm.Close(); err != nil {
+		log.Error("could not save worlds", "error", err)
+	}
+	if err := st.Close(); err != nil {
+		log.Error("could not save store", "error", err)
+	}
 }
 
-func teaHandler(gm *game.Manager) bm.ProgramHandler {
+func teaHandler(gm *game.Manager, profile string) bm.ProgramHandler {
 	return func(s ssh.Session) *tea.Program {
 		pty, _, active := s.Pty()
 
@@ -74,10 +139,16 @@ func teaHandler(gm *game.Manager) bm.ProgramHandler {
 			return nil
 		}
 
-		model := ui.New(pty.Window.Width, pty.Window.Height, gm)
-		p := tea.NewProgram(&model, tea.WithInput(s), tea.WithOutput(s), tea.WithAltScreen())
+		r := lipgloss.NewRenderer(s)
+		r.SetColorProfile(sessionColorProfile(profile, pty, s.Environ()))
+
+		model := ui.New(pty.Window.Width, pty.Window.Height, gm, r)
+		client := game.NewClient()
+		p := tea.NewProgram(&model, tea.WithInput(s), tea.WithOutput(client.Writer(s)), tea.WithAltScreen(), tea.WithMouseCellMotion())
+		client.Start(p)
 
-		playerId := gm.Connect(p)
+		key, nickname := identify(s)
+		playerId := gm.Connect(client, key, nickname)
 		s.Context().SetValue("playerId", playerId)
 
 		go func() {
@@ -88,10 +159,58 @@ func teaHandler(gm *game.Manager) bm.ProgramHandler {
 	}
 }
 
+// keyExtension holds the fingerprint of the key a connection authenticated with
+const keyExtension = "pubkey-fp"
+
+// withVerifiedKeys accepts any public key, giving each its own permissions.
+// ssh.Session.PublicKey can't be trusted, since it's the last key offered,
+// signed or not, but the permissions kept are from the key that was signed.
+func withVerifiedKeys() ssh.Option {
+	return func(srv *ssh.Server) error {
+		srv.ServerConfigCallback = func(ctx ssh.Context) *gossh.ServerConfig {
+			return &gossh.ServerConfig{
+				PublicKeyCallback: func(conn gossh.ConnMetadata, key gossh.PublicKey) (*gossh.Permissions, error) {
+					return &gossh.Permissions{
+						Extensions: map[string]string{keyExtension: gossh.FingerprintSHA256(key)},
+					}, nil
+				},
+			}
+		}
+		return nil
+	}
+}
+
+// verifiedKey returns the fingerprint of the key the session authenticated
+// with, or empty if it didn't use one
+func verifiedKey(s ssh.Session) string {
+	conn, ok := s.Context().Value(ssh.ContextKeyConn).(*gossh.ServerConn)
+	if !ok || conn.Permissions == nil {
+		return ""
+	}
+	return conn.Permissions.Extensions[keyExtension]
+}
+
+// identify returns a persistent identity key and a default nickname.
+// Sessions without a public key are guests, with an empty key, since a
+// username can be claimed by anyone.
+func identify(s ssh.Session) (string, string) {
+	nickname := s.User()
+	if store.ValidateNickname(nickname) != nil {
+		nickname = petname.Generate(2, "-")
+		if len(nickname) > store.MaxNicknameLength {
+			nickname = nickname[:store.MaxNicknameLength]
+		}
+	}
+
+	if fp := verifiedKey(s); fp != "" {
+		return "key:" + fp, nickname
+	}
+	return "", nickname
+}
+
 // copied from wish/bubbletea b/c need to know when p.Quit() in order to trigger Disconnect()
-func MiddlewareWithProgramHandler(bth bm.ProgramHandler, cp termenv.Profile, gm *game.Manager) wish.Middleware {
+func MiddlewareWithProgramHandler(bth bm.ProgramHandler, gm *game.Manager) wish.Middleware {
 	return func(sh ssh.Handler) ssh.Handler {
-		lipgloss.SetColorProfile(cp)
 		return func(s ssh.Session) {
 			p := bth(s)
 			if p != nil {
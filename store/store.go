@@ -0,0 +1,279 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// Player is the persistent identity of someone who has connected before
+type Player struct {
+	Key       string    `json:"key"`
+	Nickname  string    `json:"nickname"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Stats     Stats     `json:"stats"`
+	Prefs     Prefs     `json:"prefs"`
+}
+
+// Prefs are per player settings for how the game looks and feels
+type Prefs struct {
+	Palette string `json:"palette"`
+	Glyphs  bool   `json:"glyphs"`
+	// Name of a keybinds preset or "custom"
+	Keymap     string              `json:"keymap"`
+	CustomKeys map[string][]string `json:"customKeys,omitempty"`
+	// Whether singleplayer stops once the board settles
+	AutoPause bool `json:"autoPause"`
+}
+
+// Stats are accumulated across every multiplayer game
+type Stats struct {
+	GamesPlayed    int           `json:"gamesPlayed"`
+	PeakCells      int           `json:"peakCells"`
+	RoundsWon      int           `json:"roundsWon"`
+	PatternsPlaced int           `json:"patternsPlaced"`
+	TimePlayed     time.Duration `json:"timePlayed"`
+}
+
+type data struct {
+	Players map[string]*Player `json:"players"`
+}
+
+// Store is a small json file backed database
+type Store struct {
+	path  string
+	mutex sync.RWMutex
+	data  data
+	// Changes waiting for the next save
+	dirty bool
+	flush *time.Timer
+}
+
+// How long to wait before saving changes that aren't worth a write of their
+// own, so a burst of them is saved once
+const flushDelay = 30 * time.Second
+
+const MaxNicknameLength = 16
+
+// Open loads the store at path, creating it if it doesn't exist yet
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path: path,
+		data: data{Players: make(map[string]*Player)},
+	}
+
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, s.save()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(bytes, &s.data); err != nil {
+		return nil, fmt.Errorf("store %v is corrupted: %w", path, err)
+	}
+	if s.data.Players == nil {
+		s.data.Players = make(map[string]*Player)
+	}
+
+	return s, nil
+}
+
+// save must be called with mutex held
+func (s *Store) save() error {
+	s.dirty = false
+
+	bytes, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	// Write then rename, so a crash never leaves a half written file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, bytes, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// saveLater must be called with mutex held
+func (s *Store) saveLater() {
+	s.dirty = true
+	if s.flush != nil {
+		return
+	}
+	s.flush = time.AfterFunc(flushDelay, func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		s.flush = nil
+		if !s.dirty {
+			return
+		}
+		if err := s.save(); err != nil {
+			log.Error("could not save store", "error", err)
+		}
+	})
+}
+
+// Close saves any changes still waiting to be saved
+func (s *Store) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.flush != nil {
+		s.flush.Stop()
+		s.flush = nil
+	}
+	if !s.dirty {
+		return nil
+	}
+	return s.save()
+}
+
+func (s *Store) Player(key string) (Player, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	p, ok := s.data.Players[key]
+	if !ok {
+		return Player{}, false
+	}
+	return *p, true
+}
+
+// Touch returns the player for key, creating it with nickname if it's new.
+// Only new players are saved right away, since every connection touches.
+func (s *Store) Touch(key, nickname string) (Player, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+
+	p, ok := s.data.Players[key]
+	if !ok {
+		p = &Player{
+			Key:       key,
+			Nickname:  nickname,
+			FirstSeen: now,
+		}
+		s.data.Players[key] = p
+	}
+	p.LastSeen = now
+
+	if ok {
+		s.saveLater()
+		return *p, nil
+	}
+	return *p, s.save()
+}
+
+func (s *Store) SetNickname(key, nickname string) error {
+	if err := ValidateNickname(nickname); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p, ok := s.data.Players[key]
+	if !ok {
+		return fmt.Errorf("player %v does not exist", key)
+	}
+	p.Nickname = nickname
+
+	return s.save()
+}
+
+func (s *Store) SetPrefs(key string, prefs Prefs) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p, ok := s.data.Players[key]
+	if !ok {
+		return fmt.Errorf("player %v does not exist", key)
+	}
+	p.Prefs = prefs
+
+	return s.save()
+}
+
+// UpdateStats applies update to the stats of the player with key
+func (s *Store) UpdateStats(key string, update func(*Stats)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p, ok := s.data.Players[key]
+	if !ok {
+		return fmt.Errorf("player %v does not exist", key)
+	}
+	update(&p.Stats)
+
+	return s.save()
+}
+
+type byRank []Player
+
+func (s byRank) Len() int {
+	return len(s)
+}
+func (s byRank) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+func (s byRank) Less(i, j int) bool {
+	if s[i].Stats.RoundsWon != s[j].Stats.RoundsWon {
+		return s[i].Stats.RoundsWon > s[j].Stats.RoundsWon
+	}
+	if s[i].Stats.PeakCells != s[j].Stats.PeakCells {
+		return s[i].Stats.PeakCells > s[j].Stats.PeakCells
+	}
+	return s[i].Stats.TimePlayed > s[j].Stats.TimePlayed
+}
+
+// Leaderboard returns up to n players who have played a game, best first
+func (s *Store) Leaderboard(n int) []Player {
+	s.mutex.RLock()
+	players := make([]Player, 0, len(s.data.Players))
+	for _, p := range s.data.Players {
+		if p.Stats.GamesPlayed > 0 {
+			players = append(players, *p)
+		}
+	}
+	s.mutex.RUnlock()
+
+	sort.Sort(byRank(players))
+	if len(players) > n {
+		players = players[:n]
+	}
+	return players
+}
+
+// ValidateNickname only allows short ascii names, so they can be safely
+// truncated and aligned in the ui
+func ValidateNickname(nickname string) error {
+	if len(nickname) == 0 {
+		return errors.New("nickname can't be empty")
+	}
+	if len(nickname) > MaxNicknameLength {
+		return fmt.Errorf("nickname can't be longer than %v characters", MaxNicknameLength)
+	}
+	for _, c := range nickname {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return errors.New("nickname can only contain letters, numbers, - and _")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStorePersistsPlayers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Touch("key:abc", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetNickname("key:abc", "second"); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := s.Touch("key:abc", "ignored")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Nickname != "second" {
+		t.Errorf("got nickname %q, want %q", p.Nickname, "second")
+	}
+}
+
+func TestValidateNickname(t *testing.T) {
+	for _, name := range []string{"", "has space", "waytoolongforanickname", "emoji🙂"} {
+		if ValidateNickname(name) == nil {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+	if err := ValidateNickname("kyle_z-1"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "store.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"a", "b", "c"} {
+		if _, err := s.Touch(key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.UpdateStats("a", func(st *Stats) {
+		st.GamesPlayed = 1
+		st.PeakCells = 100
+	})
+	s.UpdateStats("b", func(st *Stats) {
+		st.GamesPlayed = 1
+		st.RoundsWon = 1
+	})
+
+	top := s.Leaderboard(10)
+	if len(top) != 2 {
+		t.Fatalf("got %v players, want 2", len(top))
+	}
+	if top[0].Key != "b" || top[1].Key != "a" {
+		t.Errorf("got order %v, %v, want b, a", top[0].Key, top[1].Key)
+	}
+}
+
+func TestTouchSavesOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := s.Touch("key:abc", "player")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := s.Touch("key:abc", "player")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Touching a known player waits for a later save
+	saved, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := saved.Player("key:abc"); !p.LastSeen.Equal(first.LastSeen) {
+		t.Errorf("last seen saved as %v, want %v", p.LastSeen, first.LastSeen)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	saved, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := saved.Player("key:abc"); !p.LastSeen.Equal(again.LastSeen) {
+		t.Errorf("last seen saved as %v after closing, want %v", p.LastSeen, again.LastSeen)
+	}
+}
@@ -1,8 +1,22 @@
 package common
 
+import (
+	"github.com/zhengkyl/gol/game"
+	"github.com/zhengkyl/gol/ui/keybinds"
+)
+
 type Common struct {
 	Width  int
 	Height int
+	// Shared by every screen in a session, so settings apply everywhere
+	Theme *game.Theme
+	Keys  *keybinds.KeyMap
+}
+
+// Capturer is implemented by screens that sometimes need every key press,
+// like while typing into a text input
+type Capturer interface {
+	Capturing() bool
 }
 
 type CommonModel interface {
@@ -0,0 +1,31 @@
+package common
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Helper is implemented by screens to list the keys that currently do something
+type Helper interface {
+	KeyHelp() help.KeyMap
+}
+
+// NewHelp is help.New rendered for a session. Colors are fixed, because
+// adaptive ones would query the client's background color.
+func NewHelp(r *lipgloss.Renderer) help.Model {
+	keyStyle := r.NewStyle().Foreground(lipgloss.Color("246"))
+	descStyle := r.NewStyle().Foreground(lipgloss.Color("242"))
+	sepStyle := r.NewStyle().Foreground(lipgloss.Color("238"))
+
+	h := help.New()
+	h.Styles = help.Styles{
+		ShortKey:       keyStyle,
+		ShortDesc:      descStyle,
+		ShortSeparator: sepStyle,
+		Ellipsis:       sepStyle.Copy(),
+		FullKey:        keyStyle.Copy(),
+		FullDesc:       descStyle.Copy(),
+		FullSeparator:  sepStyle.Copy(),
+	}
+	return h
+}
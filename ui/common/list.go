@@ -0,0 +1,42 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ListStyles are for bordered two line list items
+type ListStyles struct {
+	Item        lipgloss.Style
+	ActiveItem  lipgloss.Style
+	Title       lipgloss.Style
+	ActiveTitle lipgloss.Style
+	Desc        lipgloss.Style
+}
+
+func NewListStyles(r *lipgloss.Renderer) ListStyles {
+	return ListStyles{
+		Item:        r.NewStyle().Border(lipgloss.HiddenBorder(), true).Padding(0, 1),
+		ActiveItem:  r.NewStyle().Border(lipgloss.RoundedBorder(), true).Padding(0, 1),
+		Title:       r.NewStyle().Bold(true),
+		ActiveTitle: r.NewStyle().Bold(true).Foreground(lipgloss.Color("207")),
+		Desc:        r.NewStyle().Foreground(lipgloss.Color("252")),
+	}
+}
+
+func AlignLeftRight(left, right string, width int) string {
+	leftW := lipgloss.Width(left)
+	rightW := lipgloss.Width(right)
+
+	spaces := width - (leftW + rightW)
+
+	if spaces < 1 {
+		if leftW > width {
+			return left[:width-1] + "…"
+		}
+		return left
+	} else {
+		return left + strings.Repeat(" ", spaces) + right
+	}
+}
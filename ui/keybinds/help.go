@@ -0,0 +1,179 @@
+package keybinds
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// helpMap lists the bindings that do something in one context
+type helpMap struct {
+	short []key.Binding
+	full  [][]key.Binding
+}
+
+func (h helpMap) ShortHelp() []key.Binding {
+	return h.short
+}
+
+func (h helpMap) FullHelp() [][]key.Binding {
+	return h.full
+}
+
+// as describes what a binding does in a context
+func as(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// Move combines every direction for help display
+func (k *KeyMap) Move() key.Binding {
+	var keys []string
+	for _, b := range []key.Binding{k.Up, k.Left, k.Down, k.Right} {
+		keys = append(keys, b.Keys()...)
+	}
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(k.MoveHelp(), "move"))
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k *KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Move(), k.Help, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k *KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Left, k.Right},
+		{k.Place, k.Enter, k.Ping, k.Emote},
+		{k.Zoom, k.Graph, k.Objects, k.Minimap, k.Camera, k.Jump, k.Cluster},
+		{k.Slower, k.Faster, k.Freeze, k.Step, k.Bots},
+		{k.Soup, k.Density, k.Search},
+		{k.Help, k.Esc, k.Quit},
+	}
+}
+
+var _ help.KeyMap = &KeyMap{}
+
+func (k *KeyMap) MenuHelp() help.KeyMap {
+	enter := as(k.Enter, "select")
+	return helpMap{
+		short: []key.Binding{as(k.Up, "up"), as(k.Down, "down"), enter, k.Help, k.Quit},
+		full: [][]key.Binding{
+			{as(k.Up, "up"), as(k.Down, "down")},
+			{enter},
+			{k.Help, k.Quit},
+		},
+	}
+}
+
+// TypingHelp is for text inputs, where only a few keys aren't text
+func (k *KeyMap) TypingHelp() help.KeyMap {
+	bindings := []key.Binding{as(k.Enter, "save"), as(k.Esc, "cancel")}
+	return helpMap{
+		short: bindings,
+		full:  [][]key.Binding{bindings},
+	}
+}
+
+// SoupsHelp is for picking a soup to replay
+func (k *KeyMap) SoupsHelp() help.KeyMap {
+	bindings := []key.Binding{as(k.Up, "up"), as(k.Down, "down"), as(k.Enter, "replay"), as(k.Esc, "close")}
+	return helpMap{
+		short: bindings,
+		full:  [][]key.Binding{bindings[:2], bindings[2:]},
+	}
+}
+
+// GameMode is what a game screen is doing
+type GameMode struct {
+	// Paused players edit their cells
+	Paused      bool
+	Multiplayer bool
+	// Looking players move the camera instead of their cursor
+	Looking bool
+	// Whether the player controls the simulation speed
+	Host bool
+}
+
+// GameHelp is for the board
+func (k *KeyMap) GameHelp(mode GameMode) help.KeyMap {
+	move := k.Move()
+	dirs := []key.Binding{k.Up, k.Down, k.Left, k.Right}
+	if mode.Looking {
+		move = as(move, "look")
+		dirs = []key.Binding{as(k.Up, "look up"), as(k.Down, "look down"), as(k.Left, "look left"), as(k.Right, "look right")}
+	}
+	esc := as(k.Esc, "menu")
+
+	actions := []key.Binding{as(k.Enter, "edit")}
+	if mode.Paused {
+		actions = []key.Binding{k.Place, as(k.Enter, "play")}
+	}
+	view := []key.Binding{k.Zoom, k.Graph}
+	if mode.Multiplayer {
+		actions = append(actions, k.Ping, k.Emote)
+		view = append(view, k.Objects, k.Minimap, k.Camera, k.Jump, k.Cluster)
+	}
+
+	full := [][]key.Binding{dirs, actions, view}
+	if mode.Host {
+		speed := []key.Binding{k.Slower, k.Faster}
+		// Singleplayer is frozen whenever it's paused
+		if mode.Multiplayer {
+			speed = append(speed, k.Freeze)
+		}
+		speed = append(speed, k.Step)
+		if mode.Multiplayer {
+			speed = append(speed, k.Bots)
+		}
+		full = append(full, speed)
+	}
+	if !mode.Multiplayer {
+		full = append(full, []key.Binding{k.Soup, k.Density, k.Search})
+	}
+	full = append(full, []key.Binding{k.Help, esc, k.Quit})
+
+	return helpMap{
+		short: append(append([]key.Binding{move}, actions...), esc, k.Help),
+		full:  full,
+	}
+}
+
+func (k *KeyMap) SettingsHelp(rebinding bool) help.KeyMap {
+	if rebinding {
+		bindings := []key.Binding{
+			// Bindings without keys are hidden, but this one takes every key
+			key.NewBinding(key.WithKeys(""), key.WithHelp("any key", "bind")),
+			as(k.Esc, "cancel"),
+		}
+		return helpMap{
+			short: bindings,
+			full:  [][]key.Binding{bindings},
+		}
+	}
+
+	change := key.NewBinding(
+		key.WithKeys(append(k.Left.Keys(), k.Right.Keys()...)...),
+		key.WithHelp(k.Left.Help().Key+"/"+k.Right.Help().Key, "change"),
+	)
+	enter := as(k.Enter, "change/rebind")
+	esc := as(k.Esc, "menu")
+	return helpMap{
+		short: []key.Binding{change, enter, esc, k.Help},
+		full: [][]key.Binding{
+			{as(k.Up, "up"), as(k.Down, "down")},
+			{change, enter},
+			{k.Help, esc, k.Quit},
+		},
+	}
+}
+
+// BackHelp is for screens that can only be left
+func (k *KeyMap) BackHelp() help.KeyMap {
+	esc := as(k.Esc, "menu")
+	return helpMap{
+		short: []key.Binding{esc, k.Help, k.Quit},
+		full:  [][]key.Binding{{esc, k.Help, k.Quit}},
+	}
+}
@@ -1,8 +1,13 @@
 package keybinds
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"fmt"
+	"strings"
 
-type keyMap struct {
+	"github.com/charmbracelet/bubbles/key"
+)
+
+type KeyMap struct {
 	Up    key.Binding
 	Down  key.Binding
 	Left  key.Binding
@@ -12,60 +17,379 @@ type keyMap struct {
 	Help  key.Binding
 	Quit  key.Binding
 	Esc   key.Binding
-	// For help display
-	// Move key.Binding
+	Ping  key.Binding
+	Emote key.Binding
+	// Minimap toggles the overview of the whole board
+	Minimap key.Binding
+	// Zoom cycles how many cells fit in a character
+	Zoom key.Binding
+	// Camera cycles how the viewport follows the cursor
+	Camera key.Binding
+	// Jump looks at the next player's cursor
+	Jump key.Binding
+	// Cluster looks at the player's biggest group of cells
+	Cluster key.Binding
+	// Graph toggles the population history
+	Graph key.Binding
+	// Objects toggles labels for known patterns on the board
+	Objects key.Binding
+	// Soup fills the middle of a singleplayer board with random cells
+	Soup key.Binding
+	// Density cycles how full soups are
+	Density key.Binding
+	// Search runs many soups to find long lived ones
+	Search key.Binding
+	// Simulation speed controls
+	Slower key.Binding
+	Faster key.Binding
+	Freeze key.Binding
+	Step   key.Binding
+	// Bots fills a lobby's empty spots with bots, or removes them
+	Bots key.Binding
+}
+
+// Action is a binding players are allowed to remap
+type Action struct {
+	Name string
+	Desc string
+}
+
+var Actions = []Action{
+	{"up", "move up"},
+	{"down", "move down"},
+	{"left", "move left"},
+	{"right", "move right"},
+	{"place", "place"},
+	{"enter", "play/pause"},
+	{"ping", "ping"},
+	{"minimap", "toggle minimap"},
+	{"zoom", "zoom"},
+	{"camera", "camera mode"},
+	{"jump", "jump to player"},
+	{"cluster", "jump to cluster"},
+	{"graph", "population graph"},
+	{"objects", "label objects"},
+	{"soup", "random soup"},
+	{"density", "soup density"},
+	{"search", "search soups"},
+	{"slower", "slower"},
+	{"faster", "faster"},
+	{"freeze", "freeze time"},
+	{"step", "step one generation"},
+	{"bots", "toggle bots"},
+}
+
+// Binding returns the binding for an action name, or nil if it can't be remapped
+func (k *KeyMap) Binding(action string) *key.Binding {
+	switch action {
+	case "up":
+		return &k.Up
+	case "down":
+		return &k.Down
+	case "left":
+		return &k.Left
+	case "right":
+		return &k.Right
+	case "place":
+		return &k.Place
+	case "enter":
+		return &k.Enter
+	case "ping":
+		return &k.Ping
+	case "minimap":
+		return &k.Minimap
+	case "zoom":
+		return &k.Zoom
+	case "camera":
+		return &k.Camera
+	case "jump":
+		return &k.Jump
+	case "cluster":
+		return &k.Cluster
+	case "graph":
+		return &k.Graph
+	case "objects":
+		return &k.Objects
+	case "soup":
+		return &k.Soup
+	case "density":
+		return &k.Density
+	case "search":
+		return &k.Search
+	case "slower":
+		return &k.Slower
+	case "faster":
+		return &k.Faster
+	case "freeze":
+		return &k.Freeze
+	case "step":
+		return &k.Step
+	case "bots":
+		return &k.Bots
+	}
+	return nil
+}
+
+// Keys returns the keys of every action that can be remapped
+func (k *KeyMap) Keys() map[string][]string {
+	keys := make(map[string][]string, len(Actions))
+	for _, a := range Actions {
+		keys[a.Name] = k.Binding(a.Name).Keys()
+	}
+	return keys
 }
 
-// // ShortHelp returns keybindings to be shown in the mini help view. It's part
-// // of the key.Map interface.
-// func (k keyMap) ShortHelp() []key.Binding {
-// 	return []key.Binding{k.Move, k.Place, k.Pause, k.Help, k.Quit}
-// }
-
-// // FullHelp returns keybindings for the expanded help view. It's part of the
-// // key.Map interface.
-// func (k keyMap) FullHelp() [][]key.Binding {
-// 	return [][]key.Binding{
-// 		{k.Up, k.Down, k.Left, k.Right}, // first column
-// 		{k.Help, k.Quit},                // second column
-// 	}
-// }
-
-var KeyBinds = keyMap{
-	Up: key.NewBinding(
-		key.WithKeys("up", "k", "w"),
-		key.WithHelp("↑/k", "move up"),
-	),
-	Down: key.NewBinding(
-		key.WithKeys("down", "j", "s"),
-		key.WithHelp("↓/j", "move down"),
-	),
-	Left: key.NewBinding(
-		key.WithKeys("left", "h", "a"),
-		key.WithHelp("←/h", "move left"),
-	),
-	Right: key.NewBinding(
-		key.WithKeys("right", "l", "d"),
-		key.WithHelp("→/l", "move right"),
-	),
-	Place: key.NewBinding(
-		key.WithKeys(" "),
-		key.WithHelp("<space>", "place"),
-	),
-	Enter: key.NewBinding(
-		key.WithKeys("enter"),
-		key.WithHelp("<enter>", "pause"),
-	),
-	Help: key.NewBinding(
-		key.WithKeys("?"),
-		key.WithHelp("?", "toggle help"),
-	),
-	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
-		key.WithHelp("q", "quit"),
-	),
-	Esc: key.NewBinding(
-		key.WithKeys("esc"),
-		key.WithHelp("<esc>", "esc"),
-	),
+// Assign makes key the only key for an action, taking it from any other
+// action, since a key can only do one thing. It fails if that would leave
+// another action with no keys, like taking enter from the menus.
+func (k *KeyMap) Assign(action, key string) error {
+	rest := make(map[string][]string)
+	for _, a := range Actions {
+		if a.Name == action {
+			continue
+		}
+		keys := k.Binding(a.Name).Keys()
+		for _, other := range keys {
+			if other != key {
+				rest[a.Name] = append(rest[a.Name], other)
+			}
+		}
+		if len(rest[a.Name]) == 0 {
+			return fmt.Errorf("%v is the only key for %v", KeyName(key), a.Desc)
+		}
+	}
+
+	for name, keys := range rest {
+		k.Rebind(name, keys...)
+	}
+	k.Rebind(action, key)
+	return nil
+}
+
+// Rebind replaces the keys of an action
+func (k *KeyMap) Rebind(action string, keys ...string) {
+	b := k.Binding(action)
+	if b == nil {
+		return
+	}
+	b.SetKeys(keys...)
+	b.SetHelp(helpKeys(keys), b.Help().Desc)
+}
+
+// EmoteIndex is which of game.Emotes a key sends, by its place in the Emote
+// binding, so it doesn't depend on what the keys are
+func (k *KeyMap) EmoteIndex(pressed string) (int, bool) {
+	for i, emote := range k.Emote.Keys() {
+		if emote == pressed {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+var keyNames = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+	" ":     "<space>",
+	"enter": "<enter>",
+	"esc":   "<esc>",
+	"tab":   "<tab>",
+}
+
+func KeyName(k string) string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	return k
+}
+
+func helpKeys(keys []string) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = KeyName(k)
+	}
+	return strings.Join(names, "/")
+}
+
+// MoveHelp summarizes the movement keys, like ↑←↓→/khjl
+func (k *KeyMap) MoveHelp() string {
+	dirs := [][]string{k.Up.Keys(), k.Left.Keys(), k.Down.Keys(), k.Right.Keys()}
+
+	var groups []string
+	for i := 0; ; i++ {
+		group := strings.Builder{}
+		for _, keys := range dirs {
+			if i < len(keys) {
+				group.WriteString(KeyName(keys[i]))
+			}
+		}
+		if group.Len() == 0 {
+			break
+		}
+		groups = append(groups, group.String())
+	}
+	return strings.Join(groups, "/")
+}
+
+func newKeyMap(up, down, left, right []string) KeyMap {
+	k := KeyMap{
+		Up:    key.NewBinding(key.WithHelp("", "move up")),
+		Down:  key.NewBinding(key.WithHelp("", "move down")),
+		Left:  key.NewBinding(key.WithHelp("", "move left")),
+		Right: key.NewBinding(key.WithHelp("", "move right")),
+		Place: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("<space>", "place"),
+		),
+		Enter: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("<enter>", "pause"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "toggle help"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "ctrl+c"),
+			key.WithHelp("q", "quit"),
+		),
+		Esc: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("<esc>", "esc"),
+		),
+		Ping: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "ping"),
+		),
+		Emote: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5"),
+			key.WithHelp("1-5", "emote"),
+		),
+		Minimap: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "minimap"),
+		),
+		Zoom: key.NewBinding(
+			key.WithKeys("z"),
+			key.WithHelp("z", "zoom"),
+		),
+		Camera: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "camera mode"),
+		),
+		Jump: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("<tab>", "jump to player"),
+		),
+		Cluster: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g", "jump to cluster"),
+		),
+		Graph: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "population graph"),
+		),
+		Objects: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "label objects"),
+		),
+		Soup: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "random soup"),
+		),
+		Density: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "soup density"),
+		),
+		Search: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "search soups"),
+		),
+		Slower: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "slower"),
+		),
+		Faster: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "faster"),
+		),
+		Freeze: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "freeze time"),
+		),
+		Step: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "step"),
+		),
+		Bots: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "toggle bots"),
+		),
+	}
+	k.Rebind("up", up...)
+	k.Rebind("down", down...)
+	k.Rebind("left", left...)
+	k.Rebind("right", right...)
+	return k
+}
+
+const CustomPreset = "custom"
+
+// Presets are ready made keymaps, in the order they are cycled through
+var Presets = []string{"default", "vim", "wasd", "arrows"}
+
+// Preset returns the named keymap, falling back to the default
+func Preset(name string) KeyMap {
+	switch name {
+	case "vim":
+		return newKeyMap([]string{"k"}, []string{"j"}, []string{"h"}, []string{"l"})
+	case "wasd":
+		return newKeyMap([]string{"w"}, []string{"s"}, []string{"a"}, []string{"d"})
+	case "arrows":
+		return newKeyMap([]string{"up"}, []string{"down"}, []string{"left"}, []string{"right"})
+	}
+	return newKeyMap(
+		[]string{"up", "k", "w"},
+		[]string{"down", "j", "s"},
+		[]string{"left", "h", "a"},
+		[]string{"right", "l", "d"},
+	)
+}
+
+// Load returns the keymap saved in a player's profile
+func Load(preset string, custom map[string][]string) KeyMap {
+	if preset != CustomPreset {
+		return Preset(preset)
+	}
+
+	k := Preset("default")
+	claimed := make(map[string]bool)
+	for action, keys := range custom {
+		if len(keys) > 0 && k.Binding(action) != nil {
+			k.Rebind(action, keys...)
+			for _, key := range keys {
+				claimed[key] = true
+			}
+		}
+	}
+
+	// Actions left with their defaults, like ones added since the keymap
+	// was saved, give up keys that were remapped to something else
+	for _, a := range Actions {
+		if len(custom[a.Name]) > 0 {
+			continue
+		}
+		var rest []string
+		for _, key := range k.Binding(a.Name).Keys() {
+			if !claimed[key] {
+				rest = append(rest, key)
+			}
+		}
+		if len(rest) > 0 {
+			k.Rebind(a.Name, rest...)
+		}
+	}
+	return k
 }
@@ -0,0 +1,96 @@
+package keybinds
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMoveHelp(t *testing.T) {
+	k := Preset("default")
+	if got, want := k.MoveHelp(), "↑←↓→/khjl/wasd"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestLoadCustom(t *testing.T) {
+	k := Load(CustomPreset, map[string][]string{"up": {"i"}, "place": {"x"}})
+
+	if got := k.Up.Keys(); !reflect.DeepEqual(got, []string{"i"}) {
+		t.Errorf("got up keys %v, want [i]", got)
+	}
+	if got := k.Place.Help().Key; got != "x" {
+		t.Errorf("got place help %q, want x", got)
+	}
+	if got := k.Down.Keys(); !reflect.DeepEqual(got, Preset("default").Down.Keys()) {
+		t.Errorf("got down keys %v, want default", got)
+	}
+}
+
+func TestGameHelp(t *testing.T) {
+	k := Preset("default")
+
+	hasPlace := func(paused bool) bool {
+		for _, b := range k.GameHelp(GameMode{Paused: paused}).ShortHelp() {
+			if b.Help().Desc == "place" {
+				return true
+			}
+		}
+		return false
+	}
+	if hasPlace(false) {
+		t.Error("place shown while playing")
+	}
+	if !hasPlace(true) {
+		t.Error("place missing while editing")
+	}
+}
+
+func TestAssign(t *testing.T) {
+	k := Preset("default")
+
+	if err := k.Assign("place", "enter"); err == nil {
+		t.Error("took the only key for enter")
+	}
+	if got := k.Enter.Keys(); len(got) == 0 {
+		t.Error("enter was left with no keys")
+	}
+
+	if err := k.Assign("place", "k"); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range k.Up.Keys() {
+		if key == "k" {
+			t.Error("k still moves up")
+		}
+	}
+
+	// What's saved loads back the same
+	loaded := Load(CustomPreset, k.Keys())
+	if !reflect.DeepEqual(loaded.Keys(), k.Keys()) {
+		t.Errorf("got %v, want %v", loaded.Keys(), k.Keys())
+	}
+}
+
+func TestLoadDropsClaimedDefaults(t *testing.T) {
+	// Saved before every action was listed
+	k := Load(CustomPreset, map[string][]string{"place": {"k"}, "up": {}})
+	for _, key := range k.Up.Keys() {
+		if key == "k" {
+			t.Error("k moves up and places")
+		}
+	}
+}
+
+func TestEmoteIndex(t *testing.T) {
+	k := Preset("default")
+	if i, ok := k.EmoteIndex("3"); !ok || i != 2 {
+		t.Errorf("3 sends emote %v, %v, want 2", i, ok)
+	}
+	k.Emote.SetKeys("f1", "f2")
+	if i, ok := k.EmoteIndex("f2"); !ok || i != 1 {
+		t.Errorf("f2 sends emote %v, %v, want 1", i, ok)
+	}
+	if _, ok := k.EmoteIndex("1"); ok {
+		t.Error("1 still sends an emote")
+	}
+}
@@ -0,0 +1,104 @@
+package leaderboard
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zhengkyl/gol/game"
+	"github.com/zhengkyl/gol/store"
+	"github.com/zhengkyl/gol/ui/common"
+)
+
+const maxPlayers = 10
+
+type model struct {
+	common  common.Common
+	styles  common.ListStyles
+	help    help.Model
+	players []store.Player
+}
+
+func New(c common.Common, gm *game.Manager) *model {
+	return &model{
+		common:  c,
+		styles:  common.NewListStyles(c.Theme.Renderer),
+		help:    common.NewHelp(c.Theme.Renderer),
+		players: gm.Leaderboard(maxPlayers),
+	}
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.common.Width = msg.Width
+		m.common.Height = msg.Height
+	}
+	return m, nil
+}
+
+func (m *model) KeyHelp() help.KeyMap {
+	return m.common.Keys.BackHelp()
+}
+
+var headerStyle = lipgloss.NewStyle().Bold(true).Padding(1, 0)
+
+func (m *model) View() string {
+	sb := strings.Builder{}
+
+	contentWidth := m.common.Width
+	if contentWidth > 60 {
+		contentWidth = 60
+	}
+	viewStyle := lipgloss.NewStyle().MarginLeft((m.common.Width - contentWidth) / 2)
+
+	sb.WriteString(headerStyle.Render("LEADERBOARD"))
+	sb.WriteString("\n")
+
+	if len(m.players) == 0 {
+		sb.WriteString(m.styles.Desc.Render("Nobody has finished a game yet"))
+		sb.WriteString("\n")
+	}
+
+	// Each item is 4 lines tall
+	visible := (m.common.Height - 5) / 4
+
+	for i, p := range m.players {
+		if i >= visible {
+			break
+		}
+
+		titleStyle := m.styles.Title
+		itemStyle := m.styles.Item
+		if i == 0 {
+			titleStyle = m.styles.ActiveTitle
+			itemStyle = m.styles.ActiveItem
+		}
+
+		title := common.AlignLeftRight(
+			fmt.Sprintf("#%d %v", i+1, p.Nickname),
+			fmt.Sprintf("%d rounds won", p.Stats.RoundsWon),
+			contentWidth-4,
+		)
+		desc := common.AlignLeftRight(
+			fmt.Sprintf("peak %d cells • %d patterns", p.Stats.PeakCells, p.Stats.PatternsPlaced),
+			fmt.Sprintf("%d games • %v", p.Stats.GamesPlayed, p.Stats.TimePlayed.Round(time.Minute)),
+			contentWidth-4,
+		)
+
+		sb.WriteString(itemStyle.Render(titleStyle.Render(title) + "\n" + m.styles.Desc.Render(desc)))
+		sb.WriteString("\n")
+	}
+
+	m.help.Width = contentWidth
+	sb.WriteString(m.help.View(m.KeyHelp()))
+
+	return viewStyle.Render(sb.String())
+}
@@ -4,12 +4,14 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/zhengkyl/gol/game"
+	"github.com/zhengkyl/gol/store"
 	"github.com/zhengkyl/gol/ui/common"
-	"github.com/zhengkyl/gol/ui/keybinds"
 	"github.com/zhengkyl/pearls/scrollbar"
 )
 
@@ -31,15 +33,31 @@ type Model struct {
 	playerId       int
 	gm             *game.Manager
 	common         common.Common
+	styles         common.ListStyles
 	lobbyInfos     []game.LobbyInfo
 	options        []listItem
 	activeIndex    int
 	scrollIndex    int
 	visibleOptions int
+	// Nickname editing
+	nicknameInput textinput.Model
+	nicknameErr   string
+	editing       bool
 }
 
-func New(common common.Common, gm *game.Manager, playerId int) *Model {
-	options := make([]listItem, 0, 2)
+const (
+	soloOption = iota
+	createOption
+	worldOption
+	nicknameOption
+	settingsOption
+	leaderboardOption
+	// Lobbies are listed after all fixed options
+	fixedOptions
+)
+
+func New(c common.Common, gm *game.Manager, playerId int) *Model {
+	options := make([]listItem, 0, fixedOptions)
 	options = append(options,
 		listItem{
 			titleLeft:  "Play singleplayer game",
@@ -50,16 +68,101 @@ func New(common common.Common, gm *game.Manager, playerId int) *Model {
 		listItem{
 			titleLeft:  "Create multiplayer lobby",
 			titleRight: "",
-			descLeft:   "Play with up to 10 players",
+			descLeft:   fmt.Sprintf("Play with up to %v players", gm.Settings().MaxPlayers),
+			descRight:  "",
+		},
+		listItem{
+			titleLeft:  "Create persistent world",
+			titleRight: "",
+			descLeft:   "Saved even when everyone leaves",
+			descRight:  "",
+		},
+		listItem{},
+		listItem{
+			titleLeft:  "Settings",
+			titleRight: "",
+			descLeft:   "Colors, player glyphs and keys",
+			descRight:  "",
+		},
+		listItem{
+			titleLeft:  "Leaderboard",
+			titleRight: "",
+			descLeft:   "Top players of all time",
 			descRight:  "",
 		},
 	)
 
-	m := &Model{common: common, gm: gm, options: options, playerId: playerId}
+	ti := textinput.New()
+	ti.Prompt = ""
+	ti.CharLimit = store.MaxNicknameLength
+
+	m := &Model{
+		common:        c,
+		styles:        common.NewListStyles(c.Theme.Renderer),
+		gm:            gm,
+		options:       options,
+		playerId:      playerId,
+		nicknameInput: ti,
+	}
 	m.visibleOptions = (m.common.Height - titleHeight) / 4
+	m.updateNicknameOption()
 	return m
 }
 
+// Capturing reports whether key presses should go to the nickname input
+func (m *Model) Capturing() bool {
+	return m.editing
+}
+
+func (m *Model) KeyHelp() help.KeyMap {
+	if m.editing {
+		return m.common.Keys.TypingHelp()
+	}
+	return m.common.Keys.MenuHelp()
+}
+
+func (m *Model) updateNicknameOption() {
+	li := listItem{
+		titleLeft: "Nickname: " + m.gm.Nickname(m.playerId),
+		descLeft:  "Press <enter> to change",
+	}
+	if m.editing {
+		li.titleLeft = "Nickname: " + m.nicknameInput.View()
+		li.descLeft = "<enter> save • <esc> cancel"
+		if m.nicknameErr != "" {
+			li.descLeft = m.nicknameErr
+		}
+	}
+	m.options[nicknameOption] = li
+}
+
+func (m *Model) updateEditing(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.common.Keys.Esc):
+			m.editing = false
+			m.nicknameInput.Blur()
+		case key.Matches(msg, m.common.Keys.Enter):
+			if err := m.gm.SetNickname(m.playerId, m.nicknameInput.Value()); err != nil {
+				m.nicknameErr = err.Error()
+			} else {
+				m.editing = false
+				m.nicknameInput.Blur()
+			}
+		default:
+			m.nicknameErr = ""
+			m.nicknameInput, cmd = m.nicknameInput.Update(msg)
+		}
+	} else {
+		m.nicknameInput, cmd = m.nicknameInput.Update(msg)
+	}
+
+	m.updateNicknameOption()
+	return m, cmd
+}
+
 func (m *Model) Init() tea.Cmd {
 	return func() tea.Msg {
 		return m.gm.LobbyInfos()
@@ -67,6 +170,14 @@ func (m *Model) Init() tea.Cmd {
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.editing {
+		switch msg.(type) {
+		case tea.WindowSizeMsg, []game.LobbyInfo:
+		default:
+			return m.updateEditing(msg)
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.common.Width = msg.Width
@@ -83,12 +194,20 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case []game.LobbyInfo:
 		m.lobbyInfos = msg
-		m.options = m.options[:2]
+		m.options = m.options[:fixedOptions]
 		for _, status := range msg {
+			desc := fmt.Sprintf("id: %v", status.Id)
+			if len(status.PlayerNames) > 0 {
+				desc = strings.Join(status.PlayerNames, ", ")
+			}
+			kind := "lobby"
+			if status.Persistent {
+				kind = "world"
+			}
 			m.options = append(m.options, listItem{
-				titleLeft:  fmt.Sprintf("Join lobby: %v", status.Name),
+				titleLeft:  fmt.Sprintf("Join %v: %v", kind, status.Name),
 				titleRight: fmt.Sprintf("%v/%v players", status.PlayerCount, status.MaxPlayers),
-				descLeft:   fmt.Sprintf("id: %v", status.Id),
+				descLeft:   desc,
 			})
 		}
 		if m.activeIndex >= len(m.options) {
@@ -98,31 +217,61 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.scrollIndex = 0
 			}
 		}
+	case tea.MouseMsg:
+		switch msg.Type {
+		case tea.MouseWheelUp:
+			m.scroll(-1)
+		case tea.MouseWheelDown:
+			m.scroll(1)
+		}
 	case tea.KeyMsg:
 		switch {
-		case key.Matches(msg, keybinds.KeyBinds.Down):
+		case key.Matches(msg, m.common.Keys.Down):
 			if m.activeIndex < len(m.options)-1 {
 				m.activeIndex++
 				if m.activeIndex == m.scrollIndex+m.visibleOptions {
 					m.scrollIndex++
 				}
 			}
-		case key.Matches(msg, keybinds.KeyBinds.Up):
+		case key.Matches(msg, m.common.Keys.Up):
 			if m.activeIndex > 0 {
 				m.activeIndex--
 				if m.activeIndex == m.scrollIndex-1 {
 					m.scrollIndex--
 				}
 			}
-		case key.Matches(msg, keybinds.KeyBinds.Enter):
+		case key.Matches(msg, m.common.Keys.Enter):
 			switch m.activeIndex {
-			case 0:
+			case soloOption:
 				return m, func() tea.Msg { return game.SoloGameMsg{} }
-			case 1:
-				lid := m.gm.CreateLobby()
+			case createOption:
+				lid, err := m.gm.CreateLobby()
+				if err != nil {
+					m.options[createOption].descLeft = err.Error()
+					return m, nil
+				}
+				return m, func() tea.Msg { return m.gm.JoinLobby(lid, m.playerId) }
+			case worldOption:
+				lid, err := m.gm.CreateWorld(m.playerId)
+				if err != nil {
+					m.options[worldOption].descLeft = err.Error()
+					return m, nil
+				}
 				return m, func() tea.Msg { return m.gm.JoinLobby(lid, m.playerId) }
+			case nicknameOption:
+				m.editing = true
+				m.nicknameErr = ""
+				m.nicknameInput.SetValue(m.gm.Nickname(m.playerId))
+				m.nicknameInput.CursorEnd()
+				cmd := m.nicknameInput.Focus()
+				m.updateNicknameOption()
+				return m, cmd
+			case settingsOption:
+				return m, func() tea.Msg { return game.SettingsMsg{} }
+			case leaderboardOption:
+				return m, func() tea.Msg { return game.LeaderboardMsg{} }
 			default:
-				activeId := m.lobbyInfos[m.activeIndex-2].Id
+				activeId := m.lobbyInfos[m.activeIndex-fixedOptions].Id
 				return m, func() tea.Msg { return m.gm.JoinLobby(activeId, m.playerId) }
 			}
 		}
@@ -130,30 +279,28 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func alignLeftRight(left, right string, width int) string {
-	leftW := lipgloss.Width(left)
-	rightW := lipgloss.Width(right)
-
-	spaces := width - (leftW + rightW)
+// scroll moves the visible options, keeping the selection on screen
+func (m *Model) scroll(delta int) {
+	maxScroll := len(m.options) - m.visibleOptions
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	m.scrollIndex += delta
+	if m.scrollIndex > maxScroll {
+		m.scrollIndex = maxScroll
+	}
+	if m.scrollIndex < 0 {
+		m.scrollIndex = 0
+	}
 
-	if spaces < 1 {
-		if leftW > width {
-			return left[:width-1] + "…"
-		}
-		return left
-	} else {
-		return left + strings.Repeat(" ", spaces) + right
+	if m.activeIndex < m.scrollIndex {
+		m.activeIndex = m.scrollIndex
+	}
+	if last := m.scrollIndex + m.visibleOptions - 1; m.activeIndex > last && last >= 0 {
+		m.activeIndex = last
 	}
 }
 
-var (
-	itemStyle        = lipgloss.NewStyle().Border(lipgloss.HiddenBorder(), true).Padding(0, 1)
-	activeItemStyle  = lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), true).Padding(0, 1)
-	titleStyle       = lipgloss.NewStyle().Bold(true)
-	activeTitleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("207"))
-	descStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
-)
-
 func (m *Model) View() string {
 	viewSb := strings.Builder{}
 	itemSb := strings.Builder{}
@@ -172,16 +319,16 @@ func (m *Model) View() string {
 
 	for i := m.scrollIndex; i < m.scrollIndex+m.visibleOptions && i < len(m.options); i++ {
 		li := m.options[i]
-		titleStyle := titleStyle
-		itemStyle := itemStyle
+		titleStyle := m.styles.Title
+		itemStyle := m.styles.Item
 		if i == m.activeIndex {
-			titleStyle = activeTitleStyle
-			itemStyle = activeItemStyle
+			titleStyle = m.styles.ActiveTitle
+			itemStyle = m.styles.ActiveItem
 		}
 		// factor in border + margin
-		itemSb.WriteString(titleStyle.Render(alignLeftRight(li.titleLeft, li.titleRight, itemWidth-4)))
+		itemSb.WriteString(titleStyle.Render(common.AlignLeftRight(li.titleLeft, li.titleRight, itemWidth-4)))
 		itemSb.WriteString("\n")
-		itemSb.WriteString(descStyle.Render(alignLeftRight(li.descLeft, li.descRight, itemWidth-4)))
+		itemSb.WriteString(m.styles.Desc.Render(common.AlignLeftRight(li.descLeft, li.descRight, itemWidth-4)))
 
 		viewSb.WriteString(itemStyle.Render(itemSb.String()))
 		viewSb.WriteString("\n")
@@ -0,0 +1,129 @@
+package multiplayer
+
+import "github.com/zhengkyl/gol/util"
+
+// cameraMode is how the viewport moves, separately from the cursor
+type cameraMode int
+
+const (
+	// cameraEdge scrolls when the cursor reaches an edge
+	cameraEdge cameraMode = iota
+	// cameraFollow keeps the cursor in the middle
+	cameraFollow
+	// cameraFree moves with the movement keys, leaving the cursor behind
+	cameraFree
+	numCameraModes
+)
+
+func (c cameraMode) String() string {
+	switch c {
+	case cameraFollow:
+		return "follow"
+	case cameraFree:
+		return "free look"
+	}
+	return "edge"
+}
+
+func (c cameraMode) Next() cameraMode {
+	return (c + 1) % numCameraModes
+}
+
+// centerOn moves the viewport so a cell is in the middle
+func (m *model) centerOn(x, y int) {
+	m.viewportPosY = util.Mod(y-m.viewportHeight/2, m.boardHeight)
+	m.viewportPosX = util.Mod(x-m.viewportWidth/2, m.boardWidth)
+}
+
+func (m *model) center() {
+	m.centerOn(m.lobby.Cursor(m.playerState.Id))
+}
+
+// look puts a cell in the middle of the viewport, detaching it from the cursor
+func (m *model) look(x, y int) {
+	m.camera = cameraFree
+	m.centerOn(x, y)
+}
+
+// resizeViewport keeps looking at the same place when the viewport changes size
+func (m *model) resizeViewport() {
+	midX := m.viewportPosX + m.viewportWidth/2
+	midY := m.viewportPosY + m.viewportHeight/2
+	m.resize()
+	if m.camera == cameraFree {
+		m.centerOn(midX, midY)
+	} else {
+		m.center()
+	}
+}
+
+// move handles the movement keys, which move the cursor unless looking around
+func (m *model) move(dx, dy int) {
+	if m.camera == cameraFree {
+		m.scroll(dx, dy)
+		return
+	}
+
+	x, y := m.lobby.Cursor(m.playerState.Id)
+	x = util.Mod(x+dx, m.boardWidth)
+	y = util.Mod(y+dy, m.boardHeight)
+	m.lobby.MoveCursor(m.playerState.Id, x, y)
+
+	if m.camera == cameraFollow {
+		m.centerOn(x, y)
+		return
+	}
+
+	// Scroll just enough to keep the cursor on screen
+	if util.Mod(x-m.viewportPosX, m.boardWidth) >= m.viewportWidth {
+		if dx < 0 {
+			m.viewportPosX = x
+		} else {
+			m.viewportPosX = util.Mod(x-m.viewportWidth+1, m.boardWidth)
+		}
+	}
+	if util.Mod(y-m.viewportPosY, m.boardHeight) >= m.viewportHeight {
+		if dy < 0 {
+			m.viewportPosY = y
+		} else {
+			m.viewportPosY = util.Mod(y-m.viewportHeight+1, m.boardHeight)
+		}
+	}
+}
+
+// scroll moves the viewport. Unless looking around, the cursor is dragged
+// along if it would leave.
+func (m *model) scroll(dx, dy int) {
+	if m.camera == cameraFollow {
+		m.camera = cameraFree
+	}
+
+	m.viewportPosX = util.Mod(m.viewportPosX+dx, m.boardWidth)
+	m.viewportPosY = util.Mod(m.viewportPosY+dy, m.boardHeight)
+
+	if m.camera == cameraFree {
+		return
+	}
+
+	x, y := m.lobby.Cursor(m.playerState.Id)
+	moved := false
+	if util.Mod(x-m.viewportPosX, m.boardWidth) >= m.viewportWidth {
+		if dx > 0 {
+			x = m.viewportPosX
+		} else {
+			x = util.Mod(m.viewportPosX+m.viewportWidth-1, m.boardWidth)
+		}
+		moved = true
+	}
+	if util.Mod(y-m.viewportPosY, m.boardHeight) >= m.viewportHeight {
+		if dy > 0 {
+			y = m.viewportPosY
+		} else {
+			y = util.Mod(m.viewportPosY+m.viewportHeight-1, m.boardHeight)
+		}
+		moved = true
+	}
+	if moved {
+		m.lobby.MoveCursor(m.playerState.Id, x, y)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -14,33 +15,81 @@ import (
 )
 
 type model struct {
+	gm          *game.Manager
+	theme       *game.Theme
+	keys        *keybinds.KeyMap
+	help        help.Model
 	playerState *game.PlayerState
 	lobby       *game.Lobby
 	boardWidth  int
 	boardHeight int
+	// Terminal size
+	width  int
+	height int
+	// Minimap is drawn right of the board, each dot covering minimapScale cells
+	showMinimap  bool
+	minimapScale int
+	// Population history is drawn below the board
+	showGraph bool
+	// Known objects are labeled, and counted below the board
+	showObjects bool
+	zoom        game.Zoom
+	camera      cameraMode
+	// Id of the player last jumped to
+	watching int
 	//
 	viewportWidth  int
 	viewportHeight int
 	viewportPosY   int
 	viewportPosX   int
+	// Whether the left button is held, and whether dragging places or removes cells
+	dragging bool
+	painting bool
 }
 
-func New(c common.Common, msg game.JoinSuccessMsg) *model {
+func New(c common.Common, gm *game.Manager, msg game.JoinSuccessMsg) *model {
+	m := &model{
+		gm:     gm,
+		theme:  c.Theme,
+		keys:   c.Keys,
+		help:   common.NewHelp(c.Theme.Renderer),
+		width:  c.Width,
+		height: c.Height,
 
-	vw := c.Width / 2
-	vh := c.Height - 2
+		lobby:       msg.Lobby,
+		playerState: msg.PlayerState,
+		boardWidth:  msg.BoardWidth,
+		boardHeight: msg.BoardHeight,
+	}
+	m.resize()
+	m.center()
+	return m
+}
 
-	return &model{
-		viewportWidth:  vw,
-		viewportHeight: vh,
+// resize fits the viewport and minimap to the terminal
+func (m *model) resize() {
+	rows := m.height - 2
+	if m.showGraph {
+		rows -= game.HistoryRows
+	}
+	if m.showObjects {
+		rows -= game.CensusRows
+	}
 
-		lobby:        msg.Lobby,
-		playerState:  msg.PlayerState,
-		boardWidth:   msg.BoardWidth,
-		boardHeight:  msg.BoardHeight,
-		viewportPosY: util.Mod(msg.PlayerState.PosY-vh/2, msg.BoardHeight),
-		viewportPosX: util.Mod(msg.PlayerState.PosX-vw/2, msg.BoardWidth),
+	mapWidth := 0
+	if m.showMinimap {
+		// At most a third of the screen, and no taller than the board
+		m.minimapScale = game.MinimapScale(m.boardWidth, m.width/3)
+		for {
+			cols, mapRows := game.MinimapSize(m.boardWidth, m.boardHeight, m.minimapScale)
+			if mapRows <= rows || cols <= 1 {
+				mapWidth = cols + 1
+				break
+			}
+			m.minimapScale++
+		}
 	}
+	m.viewportWidth, m.viewportHeight = m.zoom.Cells(m.width-mapWidth, rows)
 }
 
 func (m *model) Init() tea.Cmd {
@@ -51,12 +100,18 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case tea.WindowSizeMsg:
-		m.viewportWidth = msg.Width / 2
-		m.viewportHeight = msg.Height - 2
+		m.width = msg.Width
+		m.height = msg.Height
+		m.resize()
+
+	case tea.MouseMsg:
+		if m.lobby != nil {
+			m.updateMouse(msg)
+		}
 
 	case tea.KeyMsg:
 		switch {
-		case key.Matches(msg, keybinds.KeyBinds.Quit):
+		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
 		}
 
@@ -65,39 +120,141 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		switch {
-		case key.Matches(msg, keybinds.KeyBinds.Up):
-			m.playerState.PosY = util.Mod(m.playerState.PosY-1, m.boardHeight)
-			if m.playerState.PosY == util.Mod(m.viewportPosY-1, m.boardHeight) {
-				m.viewportPosY = m.playerState.PosY
-			}
+		case key.Matches(msg, m.keys.Up):
+			m.move(0, -1)
+		case key.Matches(msg, m.keys.Left):
+			m.move(-1, 0)
+		case key.Matches(msg, m.keys.Down):
+			m.move(0, 1)
+		case key.Matches(msg, m.keys.Right):
+			m.move(1, 0)
 
-		case key.Matches(msg, keybinds.KeyBinds.Left):
-			m.playerState.PosX = util.Mod(m.playerState.PosX-1, m.boardWidth)
-			if m.playerState.PosX == util.Mod(m.viewportPosX-1, m.boardWidth) {
-				m.viewportPosX = m.playerState.PosX
+		case key.Matches(msg, m.keys.Place):
+			m.lobby.Place(m.playerState.Id)
+		case key.Matches(msg, m.keys.Enter):
+			m.lobby.TogglePause(m.playerState.Id)
+		case key.Matches(msg, m.keys.Ping):
+			m.lobby.Ping(m.playerState.Id)
+		case key.Matches(msg, m.keys.Emote):
+			if i, ok := m.keys.EmoteIndex(msg.String()); ok {
+				m.lobby.Emote(m.playerState.Id, i)
 			}
-		case key.Matches(msg, keybinds.KeyBinds.Down):
-
-			m.playerState.PosY = util.Mod(m.playerState.PosY+1, m.boardHeight)
-			if m.playerState.PosY == util.Mod(m.viewportPosY+m.viewportHeight, m.boardHeight) {
-				m.viewportPosY = util.Mod(m.viewportPosY+1, m.boardHeight)
+		case key.Matches(msg, m.keys.Minimap):
+			m.showMinimap = !m.showMinimap
+			m.resizeViewport()
+		case key.Matches(msg, m.keys.Graph):
+			m.showGraph = !m.showGraph
+			m.resizeViewport()
+		case key.Matches(msg, m.keys.Objects):
+			m.showObjects = !m.showObjects
+			m.resizeViewport()
+		case key.Matches(msg, m.keys.Zoom):
+			m.zoom = m.zoom.Next()
+			m.resizeViewport()
+		case key.Matches(msg, m.keys.Camera):
+			m.camera = m.camera.Next()
+			// Leaving free look goes back to the cursor
+			if m.camera != cameraFree {
+				m.center()
 			}
-		case key.Matches(msg, keybinds.KeyBinds.Right):
-			m.playerState.PosX = util.Mod(m.playerState.PosX+1, m.boardWidth)
-			if m.playerState.PosX == util.Mod(m.viewportPosX+m.viewportWidth, m.boardWidth) {
-				m.viewportPosX = util.Mod(m.viewportPosX+1, m.boardWidth)
+		case key.Matches(msg, m.keys.Jump):
+			id, x, y, ok := m.lobby.NextPlayer(m.playerState.Id, m.watching)
+			if ok {
+				m.watching = id
+				m.look(x, y)
 			}
-
-		case key.Matches(msg, keybinds.KeyBinds.Place):
-			m.lobby.Place(m.playerState.Id)
-		case key.Matches(msg, keybinds.KeyBinds.Enter):
-			m.lobby.TogglePause(m.playerState.Id)
+		case key.Matches(msg, m.keys.Cluster):
+			if x, y, ok := m.lobby.LargestCluster(m.playerState.Id); ok {
+				m.look(x, y)
+			}
+		case key.Matches(msg, m.keys.Slower):
+			m.lobby.ChangeSpeed(m.playerState.Id, -1)
+		case key.Matches(msg, m.keys.Faster):
+			m.lobby.ChangeSpeed(m.playerState.Id, 1)
+		case key.Matches(msg, m.keys.Freeze):
+			m.lobby.ToggleFrozen(m.playerState.Id)
+		case key.Matches(msg, m.keys.Step):
+			m.lobby.Step(m.playerState.Id)
+		case key.Matches(msg, m.keys.Bots):
+			m.gm.ToggleBots(m.playerState.Id)
 		}
 	}
 
 	return m, nil
 }
 
+func (m *model) updateMouse(msg tea.MouseMsg) {
+	switch msg.Type {
+	case tea.MouseWheelUp, tea.MouseWheelDown:
+		// Scroll a character at a time, whatever the zoom
+		stepX, stepY := m.zoom.Cells(2, 1)
+		if msg.Type == tea.MouseWheelUp {
+			stepX, stepY = -stepX, -stepY
+		}
+		// Most terminals can't scroll sideways, so a modifier does it instead
+		if msg.Alt || msg.Ctrl {
+			m.scroll(stepX, 0)
+		} else {
+			m.scroll(0, stepY)
+		}
+
+	case tea.MouseLeft:
+		x, y, ok := m.cellAt(msg.X, msg.Y)
+		if !ok {
+			return
+		}
+		m.lobby.MoveCursor(m.playerState.Id, x, y)
+		if m.camera == cameraFollow {
+			m.center()
+		}
+
+		if !m.playerState.Paused {
+			return
+		}
+		// Terminals report a drag as more presses
+		if m.dragging {
+			m.lobby.Paint(m.playerState.Id, m.painting)
+		} else {
+			m.dragging = true
+			m.painting = m.lobby.Place(m.playerState.Id)
+		}
+
+	case tea.MouseRelease:
+		m.dragging = false
+	}
+}
+
+// cellAt translates terminal coordinates to a board cell
+func (m *model) cellAt(x, y int) (int, int, bool) {
+	// The scoreboard is above the board
+	if y < 1 {
+		return 0, 0, false
+	}
+	col, row := m.zoom.Cell(x, y-1)
+
+	width, height := m.viewportWidth, m.viewportHeight
+	if width > m.boardWidth*2 {
+		width = m.boardWidth * 2
+	}
+	if height > m.boardHeight*2 {
+		height = m.boardHeight * 2
+	}
+	if col < 0 || col >= width || row < 0 || row >= height {
+		return 0, 0, false
+	}
+
+	return util.Mod(m.viewportPosX+col, m.boardWidth), util.Mod(m.viewportPosY+row, m.boardHeight), true
+}
+
+func (m *model) KeyHelp() help.KeyMap {
+	return m.keys.GameHelp(keybinds.GameMode{
+		Paused:      m.playerState.Paused,
+		Multiplayer: true,
+		Looking:     m.camera == cameraFree,
+		Host:        m.lobby.Host() == m.playerState.Id,
+	})
+}
+
 var (
 	helpStyle = lipgloss.NewStyle().Inline(true)
 )
@@ -109,37 +266,47 @@ func (m *model) View() string {
 
 	sb := strings.Builder{}
 
-	avatarStyle := lipgloss.NewStyle().Background(lipgloss.Color(game.ColorTable[m.playerState.Color].Cell))
-
 	mode := "PLAYING"
 	if m.playerState.Paused {
-		mode = fmt.Sprintf("EDITING %d/%d cells placed", m.playerState.Placed, game.MaxPlacedCells)
+		mode = fmt.Sprintf("EDITING %d/%d cells placed", m.playerState.Placed, m.lobby.Settings().MaxPlacedCells)
+	}
+	if m.camera != cameraEdge {
+		mode += " • " + m.camera.String()
 	}
 
-	sb.WriteString(helpStyle.MaxWidth(m.viewportWidth*2).Render(
-		avatarStyle.Render("  "),
+	speed, frozen := m.lobby.Speed()
+	clock := fmt.Sprintf("gen %d • %d to round end • %s", m.lobby.Generation(), m.lobby.RoundLeft(), game.SpeedLabel(speed, frozen))
+	if m.lobby.Host() == m.playerState.Id {
+		clock += " (host)"
+	}
+
+	sb.WriteString(helpStyle.MaxWidth(m.width).Render(
+		m.theme.Avatar(m.playerState.Color),
 		fmt.Sprintf("%-30s", mode),
+		fmt.Sprintf("%-46s", clock),
 		"SCORE",
-		m.lobby.Scoreboard(),
+		m.lobby.Scoreboard(m.theme),
 	))
 
 	sb.WriteString("\n")
-	sb.WriteString(m.lobby.ViewBoard(m.viewportPosY, m.viewportPosX, m.viewportWidth, m.viewportHeight))
+	board := m.lobby.ViewBoard(m.theme, m.zoom, m.showObjects, m.viewportPosY, m.viewportPosX, m.viewportWidth, m.viewportHeight)
+	if m.showMinimap {
+		minimap := m.lobby.ViewMinimap(m.theme, m.minimapScale, m.viewportPosY, m.viewportPosX, m.viewportWidth, m.viewportHeight)
+		board = lipgloss.JoinHorizontal(lipgloss.Top, board, " ", minimap)
+	}
+	sb.WriteString(board)
 	sb.WriteString("\n")
+	if m.showGraph {
+		sb.WriteString(m.lobby.ViewHistory(m.theme, m.width))
+		sb.WriteString("\n")
+	}
+	if m.showObjects {
+		sb.WriteString(m.lobby.ViewCensus(m.theme, m.width))
+		sb.WriteString("\n")
+	}
 
-	sb.WriteString(helpStyle.MaxWidth(m.viewportWidth*2).Render(
-		"wasd/hjkl/←↑↓→",
-		"move",
-		" • ",
-		"<space>",
-		"place",
-		" • ",
-		"<enter>",
-		"play/edit",
-		" • ",
-		"<esc>",
-		"menu",
-	))
+	m.help.Width = m.width
+	sb.WriteString(m.help.View(m.KeyHelp()))
 
 	return sb.String()
 }
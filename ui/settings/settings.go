@@ -0,0 +1,261 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zhengkyl/gol/game"
+	"github.com/zhengkyl/gol/ui/common"
+	"github.com/zhengkyl/gol/ui/keybinds"
+)
+
+const (
+	paletteOption = iota
+	glyphsOption
+	keymapOption
+	autoPauseOption
+	// Actions to rebind are listed after all fixed options
+	fixedOptions
+)
+
+var numOptions = fixedOptions + len(keybinds.Actions)
+
+type model struct {
+	common      common.Common
+	styles      common.ListStyles
+	help        help.Model
+	gm          *game.Manager
+	playerId    int
+	activeIndex int
+	keymap      string
+	autoPause   bool
+	// Whether the next key press rebinds the active action
+	rebinding bool
+	err       string
+}
+
+func New(c common.Common, gm *game.Manager, playerId int) *model {
+	prefs := gm.Prefs(playerId)
+	keymap := prefs.Keymap
+	if keymap == "" {
+		keymap = keybinds.Presets[0]
+	}
+
+	return &model{
+		common:    c,
+		styles:    common.NewListStyles(c.Theme.Renderer),
+		help:      common.NewHelp(c.Theme.Renderer),
+		gm:        gm,
+		playerId:  playerId,
+		keymap:    keymap,
+		autoPause: prefs.AutoPause,
+	}
+}
+
+func (m *model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *model) Capturing() bool {
+	return m.rebinding
+}
+
+func (m *model) KeyHelp() help.KeyMap {
+	return m.common.Keys.SettingsHelp(m.rebinding)
+}
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.common.Width = msg.Width
+		m.common.Height = msg.Height
+
+	case tea.KeyMsg:
+		if m.rebinding {
+			m.rebind(msg)
+			return m, nil
+		}
+
+		keys := m.common.Keys
+		switch {
+		case key.Matches(msg, keys.Down):
+			if m.activeIndex < numOptions-1 {
+				m.activeIndex++
+			}
+		case key.Matches(msg, keys.Up):
+			if m.activeIndex > 0 {
+				m.activeIndex--
+			}
+		case key.Matches(msg, keys.Left):
+			m.change(-1)
+		case key.Matches(msg, keys.Right),
+			key.Matches(msg, keys.Enter),
+			key.Matches(msg, keys.Place):
+			if m.activeIndex >= fixedOptions {
+				m.rebinding = true
+				m.err = ""
+				return m, nil
+			}
+			m.change(1)
+		}
+	}
+	return m, nil
+}
+
+// These keys work the same everywhere, so they can't be rebound
+func reserved(keys *keybinds.KeyMap, msg tea.KeyMsg) bool {
+	return key.Matches(msg, keys.Quit) ||
+		key.Matches(msg, keys.Esc) ||
+		key.Matches(msg, keys.Help) ||
+		key.Matches(msg, keys.Emote)
+}
+
+func (m *model) rebind(msg tea.KeyMsg) {
+	m.rebinding = false
+
+	keys := m.common.Keys
+	if key.Matches(msg, keys.Esc) {
+		return
+	}
+	if reserved(keys, msg) {
+		m.err = fmt.Sprintf("%v is reserved", keybinds.KeyName(msg.String()))
+		return
+	}
+
+	action := keybinds.Actions[m.activeIndex-fixedOptions].Name
+	if err := keys.Assign(action, msg.String()); err != nil {
+		m.err = err.Error()
+		return
+	}
+
+	m.keymap = keybinds.CustomPreset
+	m.save()
+}
+
+func (m *model) change(delta int) {
+	theme := m.common.Theme
+
+	switch m.activeIndex {
+	case paletteOption:
+		theme.Palette = (theme.Palette + delta + len(game.Palettes)) % len(game.Palettes)
+	case glyphsOption:
+		theme.Glyphs = !theme.Glyphs
+	case keymapOption:
+		// Custom keymaps are left by picking a preset
+		i := 0
+		for j, name := range keybinds.Presets {
+			if name == m.keymap {
+				i = j + delta
+			}
+		}
+		i = (i + len(keybinds.Presets)) % len(keybinds.Presets)
+		m.keymap = keybinds.Presets[i]
+		*m.common.Keys = keybinds.Preset(m.keymap)
+	case autoPauseOption:
+		m.autoPause = !m.autoPause
+	default:
+		return
+	}
+
+	m.save()
+}
+
+func (m *model) save() {
+	prefs := m.gm.Prefs(m.playerId)
+	prefs.Palette = game.Palettes[m.common.Theme.Palette].Name
+	prefs.Glyphs = m.common.Theme.Glyphs
+	prefs.Keymap = m.keymap
+	prefs.AutoPause = m.autoPause
+	prefs.CustomKeys = nil
+	if m.keymap == keybinds.CustomPreset {
+		prefs.CustomKeys = m.common.Keys.Keys()
+	}
+
+	m.err = ""
+	if err := m.gm.SetPrefs(m.playerId, prefs); err != nil {
+		m.err = err.Error()
+	}
+}
+
+var headerStyle = lipgloss.NewStyle().Bold(true).Padding(1, 0)
+
+func (m *model) View() string {
+	theme := m.common.Theme
+
+	contentWidth := m.common.Width
+	if contentWidth > 60 {
+		contentWidth = 60
+	}
+	viewStyle := lipgloss.NewStyle().MarginLeft((m.common.Width - contentWidth) / 2)
+
+	preview := strings.Builder{}
+	for color := 1; color <= game.MaxPlayers; color++ {
+		preview.WriteString(theme.Avatar(color))
+		preview.WriteString(" ")
+	}
+
+	onOff := func(on bool) string {
+		if on {
+			return "on"
+		}
+		return "off"
+	}
+
+	items := []struct {
+		title string
+		value string
+		desc  string
+	}{
+		{"Palette", "◂ " + game.Palettes[theme.Palette].Name + " ▸", preview.String()},
+		{"Player glyphs", "◂ " + onOff(theme.Glyphs) + " ▸", "Mark cells with a symbol per player"},
+		{"Keys", "◂ " + m.keymap + " ▸", "Select an action below to rebind it"},
+		{"Auto-pause", "◂ " + onOff(m.autoPause) + " ▸", "Pause singleplayer once the board settles"},
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(headerStyle.Render("SETTINGS"))
+	sb.WriteString("\n")
+
+	for i, item := range items {
+		titleStyle := m.styles.Title
+		itemStyle := m.styles.Item
+		if i == m.activeIndex {
+			titleStyle = m.styles.ActiveTitle
+			itemStyle = m.styles.ActiveItem
+		}
+
+		title := common.AlignLeftRight(item.title, item.value, contentWidth-4)
+		sb.WriteString(itemStyle.Render(titleStyle.Render(title) + "\n" + m.styles.Desc.Render(item.desc)))
+		sb.WriteString("\n")
+	}
+
+	// Actions are a single line each to fit on small screens
+	for i, a := range keybinds.Actions {
+		titleStyle := m.styles.Title
+		marker := "  "
+		keys := m.common.Keys.Binding(a.Name).Help().Key
+		if fixedOptions+i == m.activeIndex {
+			titleStyle = m.styles.ActiveTitle
+			marker = "> "
+			if m.rebinding {
+				keys = "press a key..."
+			}
+		}
+		sb.WriteString(titleStyle.Render(common.AlignLeftRight(marker+a.Desc, keys, contentWidth-2)))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	if m.err != "" {
+		sb.WriteString(m.err)
+		sb.WriteString("\n")
+	}
+	m.help.Width = contentWidth
+	sb.WriteString(m.help.View(m.KeyHelp()))
+
+	return viewStyle.Render(sb.String())
+}
@@ -1,12 +1,18 @@
 package singleplayer
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
+	"github.com/zhengkyl/gol/game"
+	"github.com/zhengkyl/gol/game/analysis"
 	"github.com/zhengkyl/gol/game/life"
+	"github.com/zhengkyl/gol/ui/common"
 	"github.com/zhengkyl/gol/ui/keybinds"
+	"github.com/zhengkyl/gol/util"
 
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -17,31 +23,188 @@ const (
 	player = 1
 )
 
+var (
+	deadColor   = lipgloss.Color("0")
+	aliveColor  = lipgloss.Color("227")
+	cursorColor = lipgloss.Color("15")
+)
+
 type model struct {
+	common      common.Common
+	help        help.Model
+	styles      common.ListStyles
+	deadStyle   lipgloss.Style
+	aliveStyle  lipgloss.Style
+	graphStyle  lipgloss.Style
 	boardWidth  int
 	boardHeight int
 	board       [][]life.Cell
 	posX        int
 	posY        int
 	paused      bool
+	// Index into game.Speeds
+	speed      int
+	generation int
+	// Ticks from before the last speed change or pause are ignored
+	ticks int
+	zoom  game.Zoom
+	// Living cells at each recent generation, drawn above the status line
+	history   []int
+	showGraph bool
+	// Notices when the board settles, which is shown in the status line.
+	// Both are reset when the board is edited.
+	tracker   *analysis.Tracker
+	stable    *analysis.Stability
+	autoPause bool
+	// Whether the left button is held, and whether dragging places or removes cells
+	dragging bool
+	painting bool
+	// Soups are numbered by seed, and the one on the board is shown
+	seed    int64
+	density int
+	soup    *analysis.Soup
+	// Longest lived soups from the last search, listed instead of the
+	// board until one is picked
+	searching bool
+	results   []analysis.SoupResult
+	selected  int
 }
 
-func New(width, height int) *model {
+// New creates a board filling the screen, except for the status line.
+// With autoPause, the game pauses once the board settles.
+func New(c common.Common, autoPause bool) *model {
+	width := c.Width / 2
+	height := c.Height - 1
+
 	return &model{
+		common:      c,
+		help:        common.NewHelp(c.Theme.Renderer),
+		styles:      common.NewListStyles(c.Theme.Renderer),
+		deadStyle:   c.Theme.Renderer.NewStyle().Background(deadColor),
+		aliveStyle:  c.Theme.Renderer.NewStyle().Background(aliveColor),
+		graphStyle:  c.Theme.Renderer.NewStyle().Foreground(aliveColor),
 		boardWidth:  width,
 		boardHeight: height,
 		board:       life.NewBoard(width, height),
 		posX:        width / 2,
 		posY:        height / 2,
 		paused:      true,
+		speed:       game.DefaultSpeed,
+		autoPause:   autoPause,
+		seed:        time.Now().UnixNano(),
+		density:     defaultDensity,
+	}
+}
+
+type tickMsg struct {
+	id int
+}
+
+// tick schedules the next generation at the current speed
+func (m *model) tick() tea.Cmd {
+	m.ticks++
+	id := m.ticks
+	return tea.Tick(time.Second/time.Duration(game.Speeds[m.speed]), func(t time.Time) tea.Msg {
+		return tickMsg{id}
+	})
+}
+
+// step runs one generation
+func (m *model) step() {
+	if m.tracker == nil {
+		m.tracker = analysis.NewTracker()
+		m.tracker.Add(m.board, m.generation)
+	}
+
+	m.board = life.NextBoard(m.board)
+	m.generation++
+	m.history = game.Record(m.history, analysis.Population(m.board))
+
+	if m.stable != nil {
+		return
+	}
+	if s, ok := m.tracker.Add(m.board, m.generation); ok {
+		m.stable = &s
+		if m.autoPause {
+			m.paused = true
+		}
+	}
+}
+
+// edited forgets the board's past states, since they can't repeat anymore
+func (m *model) edited() {
+	m.tracker = nil
+	m.stable = nil
+	m.soup = nil
+}
+
+// Capturing is true while soups are listed, so esc closes the list instead
+// of leaving
+func (m *model) Capturing() bool {
+	return m.results != nil
+}
+
+// statusRows is how many lines are drawn below the board
+func (m *model) statusRows() int {
+	if m.showGraph {
+		return 2
+	}
+	return 1
+}
+
+// changeSpeed moves delta steps through game.Speeds
+func (m *model) changeSpeed(delta int) tea.Cmd {
+	m.speed = util.Max(0, util.Min(m.speed+delta, len(game.Speeds)-1))
+	if m.paused {
+		return nil
+	}
+	// Otherwise a slow tick could hold up a faster speed
+	return m.tick()
+}
+
+// viewSize is how many cells fit on the screen at the current zoom
+func (m *model) viewSize() (int, int) {
+	width, height := m.zoom.Cells(m.common.Width, m.common.Height-m.statusRows())
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
 	}
+	return width, height
 }
 
-type tickMsg struct{}
+// resize fits the board to the screen at the current zoom, keeping
+// whatever cells still fit
+func (m *model) resize() {
+	width, height := m.viewSize()
+	// Changing the view without changing the board isn't an edit
+	if width == m.boardWidth && height == m.boardHeight {
+		return
+	}
 
-var tickOnce = tea.Tick(time.Second/5, func(t time.Time) tea.Msg {
-	return tickMsg{}
-})
+	board := life.NewBoard(width, height)
+	for y := 0; y < height && y < m.boardHeight; y++ {
+		copy(board[y], m.board[y])
+	}
+	// Only cells cut off change how the board runs. Otherwise its past
+	// states are still worth remembering.
+	if analysis.Population(board) != analysis.Population(m.board) {
+		m.edited()
+	}
+	m.board = board
+	m.boardWidth = width
+	m.boardHeight = height
+	// Soups only run the same on the size they started on
+	m.soup = nil
+
+	if m.posX >= width {
+		m.posX = width - 1
+	}
+	if m.posY >= height {
+		m.posY = height - 1
+	}
+}
 
 func (m *model) Init() tea.Cmd {
 	return nil
@@ -50,61 +213,159 @@ func (m *model) Init() tea.Cmd {
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		return New(msg.Width/2, msg.Height-1), nil
+		m.common.Width = msg.Width
+		m.common.Height = msg.Height
+		m.resize()
 
 	case tea.KeyMsg:
+		if m.results != nil {
+			return m, m.updateResults(msg)
+		}
+
 		switch {
-		case key.Matches(msg, keybinds.KeyBinds.Quit):
+		case key.Matches(msg, m.common.Keys.Quit):
 			return m, tea.Quit
-		case key.Matches(msg, keybinds.KeyBinds.Up):
+		case key.Matches(msg, m.common.Keys.Up):
 			m.posY = (m.posY - 1 + m.boardHeight) % m.boardHeight
-		case key.Matches(msg, keybinds.KeyBinds.Left):
+		case key.Matches(msg, m.common.Keys.Left):
 			m.posX = (m.posX - 1 + m.boardWidth) % m.boardWidth
-		case key.Matches(msg, keybinds.KeyBinds.Down):
+		case key.Matches(msg, m.common.Keys.Down):
 			m.posY = (m.posY + 1 + m.boardHeight) % m.boardHeight
-		case key.Matches(msg, keybinds.KeyBinds.Right):
+		case key.Matches(msg, m.common.Keys.Right):
 			m.posX = (m.posX + 1 + m.boardWidth) % m.boardWidth
-		case key.Matches(msg, keybinds.KeyBinds.Place):
+		case key.Matches(msg, m.common.Keys.Place):
 			if m.board[m.posY][m.posX].Player == dead {
 				m.board[m.posY][m.posX].Player = player
 			} else {
 				m.board[m.posY][m.posX].Player = dead
 			}
-		case key.Matches(msg, keybinds.KeyBinds.Enter):
+			m.edited()
+		case key.Matches(msg, m.common.Keys.Graph):
+			m.showGraph = !m.showGraph
+			m.resize()
+		case key.Matches(msg, m.common.Keys.Zoom):
+			m.zoom = m.zoom.Next()
+			m.resize()
+		case key.Matches(msg, m.common.Keys.Enter):
 			m.paused = !m.paused
 			if !m.paused {
-				return m, tickOnce
+				return m, m.tick()
+			}
+		case key.Matches(msg, m.common.Keys.Slower):
+			return m, m.changeSpeed(-1)
+		case key.Matches(msg, m.common.Keys.Faster):
+			return m, m.changeSpeed(1)
+		case key.Matches(msg, m.common.Keys.Step):
+			if m.paused {
+				m.step()
+			}
+		case key.Matches(msg, m.common.Keys.Soup):
+			m.seed++
+			return m, m.playSoup(m.newSoup(m.seed), m.boardWidth, m.boardHeight)
+		case key.Matches(msg, m.common.Keys.Density):
+			m.density = (m.density + 1) % len(densities)
+		case key.Matches(msg, m.common.Keys.Search):
+			if !m.searching {
+				return m, m.search()
 			}
 		}
 
+	case searchDoneMsg:
+		m.searching = false
+		m.results = msg.results
+		m.selected = 0
+
+	case tea.MouseMsg:
+		m.updateMouse(msg)
+
 	case tickMsg:
-		if !m.paused {
-			m.board = life.NextBoard(m.board)
-			return m, tickOnce
+		if !m.paused && msg.id == m.ticks {
+			m.step()
+			// Auto-pause might have stopped it
+			if !m.paused {
+				return m, m.tick()
+			}
 		}
 	}
 
 	return m, nil
 }
 
-var deadStyle = lipgloss.NewStyle().Background(lipgloss.Color("0"))
-var aliveStyle = lipgloss.NewStyle().Background(lipgloss.Color("227"))
+func (m *model) updateMouse(msg tea.MouseMsg) {
+	switch msg.Type {
+	case tea.MouseLeft:
+		// The board starts at the top left corner
+		x, y := m.zoom.Cell(msg.X, msg.Y)
+		if x < 0 || x >= m.boardWidth || y < 0 || y >= m.boardHeight {
+			return
+		}
+		m.posX = x
+		m.posY = y
+
+		// Terminals report a drag as more presses
+		if !m.dragging {
+			m.dragging = true
+			m.painting = m.board[y][x].Player == dead
+		}
+		if m.painting {
+			m.board[y][x].Player = player
+		} else {
+			m.board[y][x].Player = dead
+		}
+		m.edited()
+
+	case tea.MouseRelease:
+		m.dragging = false
+	}
+}
+
+func (m *model) KeyHelp() help.KeyMap {
+	if m.results != nil {
+		return m.common.Keys.SoupsHelp()
+	}
+	return m.common.Keys.GameHelp(keybinds.GameMode{Paused: m.paused, Host: true})
+}
 
 func (m *model) View() string {
+	if m.results != nil {
+		return m.resultsView()
+	}
 
 	sb := strings.Builder{}
 
+	if m.zoom != game.ZoomNormal {
+		sb.WriteString(game.RenderZoomed(m.common.Theme, m.zoom, m.boardWidth, m.boardHeight, func(x, y int) game.Pixel {
+			var p game.Pixel
+			if m.board[y][x].Player == player {
+				p.Color = aliveColor
+			}
+			if x == m.posX && y == m.posY {
+				p.Cursor = cursorColor
+			}
+			return p
+		}))
+		sb.WriteString("\n")
+		return sb.String() + m.statusView()
+	}
+
+	glyphs := m.common.Theme.ShowGlyphs()
+
 	for y := range m.board {
 		for x, cell := range m.board[y] {
 
+			cursor := y == m.posY && x == m.posX
+
 			pixel := "  "
-			if y == m.posY && x == m.posX {
+			if cursor {
 				pixel = "[]"
 			}
 
-			style := deadStyle
+			style := m.deadStyle
 			if cell.Player == player {
-				style = aliveStyle
+				style = m.aliveStyle
+				if glyphs && !cursor {
+					pixel = game.Glyphs[player]
+				}
 			}
 
 			sb.WriteString(style.Render(pixel))
@@ -113,10 +374,37 @@ func (m *model) View() string {
 		sb.WriteString("\n")
 	}
 
+	return sb.String() + m.statusView()
+}
+
+func (m *model) statusView() string {
+	graph := ""
+	if m.showGraph {
+		label := fmt.Sprintf("%5d", analysis.Population(m.board))
+		width := util.Max(0, m.common.Width-len(label)-1)
+		graph = m.graphStyle.Render(game.Sparkline(m.history, game.HistoryMax(width, m.history), width)) + " " + label + "\n"
+	}
+
 	status := "Playing"
 	if m.paused {
 		status = "Paused "
 	}
-	sb.WriteString(status + "  •  wasd/hjkl/←↑↓→ move  •  <space> place  •  <enter> play/pause  •  <esc> menu")
-	return sb.String()
+	status += fmt.Sprintf("  gen %d • %s", m.generation, game.SpeedLabel(m.speed, false))
+	// Density is shown even without a soup, so changing it shows up
+	if m.soup != nil {
+		status += fmt.Sprintf(" • soup #%d %d%%", m.soup.Seed, m.soup.Density)
+	} else {
+		status += fmt.Sprintf(" • soup %d%%", densities[m.density])
+	}
+	if m.stable != nil {
+		status += " • " + m.stable.String()
+		if census := m.census(); census != "" {
+			status += ": " + census
+		}
+	}
+	if m.searching {
+		status += fmt.Sprintf(" • searching %d soups...", searchCount)
+	}
+	m.help.Width = m.common.Width - lipgloss.Width(status) - 2
+	return graph + status + "  " + m.help.View(m.KeyHelp())
 }
@@ -0,0 +1,153 @@
+package singleplayer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zhengkyl/gol/game/analysis"
+	"github.com/zhengkyl/gol/game/life"
+	"github.com/zhengkyl/gol/ui/common"
+	"github.com/zhengkyl/gol/util"
+)
+
+// Percents of cells alive in a new soup
+var densities = []int{20, 30, 40, 50, 60}
+
+const (
+	defaultDensity = 2
+	// Soups are a square this many cells wide in the middle of the board
+	soupSize = 16
+	// How many soups a search runs, and for how long at most
+	searchCount = 200
+	searchLimit = 5000
+	// How many of the longest lived soups a search lists
+	searchKeep = 10
+)
+
+type searchDoneMsg struct {
+	results []analysis.SoupResult
+}
+
+func (m *model) newSoup(seed int64) analysis.Soup {
+	return analysis.Soup{Seed: seed, Size: soupSize, Density: densities[m.density]}
+}
+
+// playSoup clears the board for a soup and starts running it on a width x
+// height board, or as much of one as fits on the screen
+func (m *model) playSoup(soup analysis.Soup, width, height int) tea.Cmd {
+	viewWidth, viewHeight := m.viewSize()
+	width = util.Min(width, viewWidth)
+	height = util.Min(height, viewHeight)
+
+	m.board = life.NewBoard(width, height)
+	m.boardWidth = width
+	m.boardHeight = height
+	if m.posX >= width {
+		m.posX = width - 1
+	}
+	if m.posY >= height {
+		m.posY = height - 1
+	}
+	soup.Fill(m.board, player)
+	m.generation = 0
+	m.history = nil
+	m.edited()
+	m.soup = &soup
+
+	m.paused = false
+	return m.tick()
+}
+
+// search runs soups with the next seeds without drawing them
+func (m *model) search() tea.Cmd {
+	soup := m.newSoup(m.seed + 1)
+	m.seed += searchCount
+	m.searching = true
+
+	width, height := m.boardWidth, m.boardHeight
+	return func() tea.Msg {
+		return searchDoneMsg{analysis.Search(soup, searchCount, width, height, searchLimit, searchKeep)}
+	}
+}
+
+// updateResults picks a soup from the list to replay
+func (m *model) updateResults(msg tea.KeyMsg) tea.Cmd {
+	keys := m.common.Keys
+	switch {
+	case key.Matches(msg, keys.Quit):
+		return tea.Quit
+	case key.Matches(msg, keys.Esc), key.Matches(msg, keys.Search):
+		m.results = nil
+	case key.Matches(msg, keys.Up):
+		if m.selected > 0 {
+			m.selected--
+		}
+	case key.Matches(msg, keys.Down):
+		if m.selected < len(m.results)-1 {
+			m.selected++
+		}
+	case key.Matches(msg, keys.Enter):
+		if len(m.results) == 0 {
+			m.results = nil
+			return nil
+		}
+		// Replayed on the board it was found on if it fits, since the edges
+		// change how it runs
+		r := m.results[m.selected]
+		m.results = nil
+		return m.playSoup(r.Soup, r.Width, r.Height)
+	}
+	return nil
+}
+
+// census lists what's left on the board, most common first
+func (m *model) census() string {
+	var counts []string
+	for _, c := range analysis.Sorted(analysis.Census(analysis.Identify(m.board))[player]) {
+		counts = append(counts, fmt.Sprintf("%d %s", c.N, c.Name))
+	}
+	return strings.Join(counts, ", ")
+}
+
+// resultsView lists the soups found by a search
+func (m *model) resultsView() string {
+	contentWidth := m.common.Width
+	if contentWidth > 60 {
+		contentWidth = 60
+	}
+	r := m.common.Theme.Renderer
+	viewStyle := r.NewStyle().MarginLeft((m.common.Width - contentWidth) / 2)
+	headerStyle := r.NewStyle().Bold(true).Padding(1, 0)
+
+	sb := strings.Builder{}
+	sb.WriteString(headerStyle.Render("LONGEST LIVED SOUPS"))
+	sb.WriteString("\n")
+
+	if len(m.results) == 0 {
+		sb.WriteString(m.styles.Desc.Render(fmt.Sprintf("None of %d soups settled within %d generations", searchCount, searchLimit)))
+		sb.WriteString("\n")
+	}
+	for i, r := range m.results {
+		titleStyle := m.styles.Title
+		marker := "  "
+		if i == m.selected {
+			titleStyle = m.styles.ActiveTitle
+			marker = "> "
+		}
+		left := fmt.Sprintf("%sseed %d", marker, r.Soup.Seed)
+		right := fmt.Sprintf("%d generations, period %d", r.Lifespan(), r.Stability.Period)
+		if r.Stability.Extinct {
+			right = fmt.Sprintf("%d generations, died out", r.Lifespan())
+		}
+		sb.WriteString(titleStyle.Render(common.AlignLeftRight(left, right, contentWidth-2)))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	m.help.Width = contentWidth
+	sb.WriteString(m.help.View(m.KeyHelp()))
+
+	return viewStyle.Render(sb.String())
+}
@@ -1,13 +1,17 @@
 package ui
 
 import (
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/zhengkyl/gol/game"
 	"github.com/zhengkyl/gol/ui/common"
 	"github.com/zhengkyl/gol/ui/keybinds"
+	"github.com/zhengkyl/gol/ui/leaderboard"
 	"github.com/zhengkyl/gol/ui/menu"
 	"github.com/zhengkyl/gol/ui/multiplayer"
+	"github.com/zhengkyl/gol/ui/settings"
 	"github.com/zhengkyl/gol/ui/singleplayer"
 )
 
@@ -18,6 +22,8 @@ const (
 	menuScreen
 	singleplayerScreen
 	multiplayerScreen
+	leaderboardScreen
+	settingsScreen
 )
 
 type model struct {
@@ -27,13 +33,20 @@ type model struct {
 	menu     *menu.Model
 	game     tea.Model
 	screen   screen
+	// Whether the full help overlay covers the screen
+	showHelp bool
 }
 
-func New(width, height int, gm *game.Manager) model {
+func New(width, height int, gm *game.Manager, r *lipgloss.Renderer) model {
 	return model{
 		screen: loadingScreen,
-		common: common.Common{Width: width, Height: height},
-		gm:     gm,
+		common: common.Common{
+			Width:  width,
+			Height: height,
+			Theme:  &game.Theme{Renderer: r},
+			Keys:   &keybinds.KeyMap{},
+		},
+		gm: gm,
 	}
 }
 
@@ -52,24 +65,59 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case PlayerId:
 		m.playerId = int(msg)
 
+		prefs := m.gm.Prefs(m.playerId)
+		m.common.Theme.Palette = game.PaletteIndex(prefs.Palette)
+		m.common.Theme.Glyphs = prefs.Glyphs
+		*m.common.Keys = keybinds.Load(prefs.Keymap, prefs.CustomKeys)
+
 		m.menu = menu.New(m.common, m.gm, m.playerId)
 		m.screen = menuScreen
 
-		return m, m.menu.Init()
+		// Reconnecting players go straight back into their lobby
+		return m, tea.Batch(m.menu.Init(), func() tea.Msg {
+			return m.gm.Resume(m.playerId)
+		})
 
 	case game.JoinSuccessMsg:
-		m.game = multiplayer.New(common.Common{
-			Width: m.common.Width, Height: m.common.Height,
-		}, msg)
+		m.game = multiplayer.New(m.common, m.gm, msg)
 		m.screen = multiplayerScreen
 	case game.SoloGameMsg:
-		m.game = singleplayer.New(m.common.Width/2, m.common.Height)
+		m.game = singleplayer.New(m.common, m.gm.Prefs(m.playerId).AutoPause)
 		m.screen = singleplayerScreen
+	case game.LeaderboardMsg:
+		m.game = leaderboard.New(m.common, m.gm)
+		m.screen = leaderboardScreen
+	case game.SettingsMsg:
+		m.game = settings.New(m.common, m.gm, m.playerId)
+		m.screen = settingsScreen
+	case tea.MouseMsg:
+		if m.showHelp {
+			return m, nil
+		}
 	case tea.KeyMsg:
-		if key.Matches(msg, keybinds.KeyBinds.Quit) {
+		if m.capturing() {
+			break
+		}
+		if m.showHelp {
+			// Only closing help or quitting works while it's open
+			if key.Matches(msg, m.common.Keys.Help) || key.Matches(msg, m.common.Keys.Esc) {
+				m.showHelp = false
+				return m, nil
+			}
+			if !key.Matches(msg, m.common.Keys.Quit) {
+				return m, nil
+			}
+		}
+		if key.Matches(msg, m.common.Keys.Help) && m.screen != loadingScreen {
+			m.showHelp = true
+			return m, nil
+		}
+		if key.Matches(msg, m.common.Keys.Quit) {
+			// Quitting on purpose gives up the spot held for reconnects
+			m.gm.LeaveLobby(m.playerId)
 			return m, tea.Quit
 		}
-		if key.Matches(msg, keybinds.KeyBinds.Esc) {
+		if key.Matches(msg, m.common.Keys.Esc) {
 			m.gm.LeaveLobby(m.playerId)
 			m.game = nil
 			m.screen = menuScreen
@@ -83,21 +131,69 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		_, cmd = m.game.Update(msg)
 	case multiplayerScreen:
 		_, cmd = m.game.Update(msg)
+	case leaderboardScreen, settingsScreen:
+		_, cmd = m.game.Update(msg)
 	case menuScreen:
 		_, cmd = m.menu.Update(msg)
 	}
 	return m, cmd
 }
 
+func (m *model) active() tea.Model {
+	if m.screen == menuScreen {
+		return m.menu
+	}
+	return m.game
+}
+
+// capturing reports whether the current screen wants every key press
+func (m *model) capturing() bool {
+	c, ok := m.active().(common.Capturer)
+	return ok && c.Capturing()
+}
+
 func (m *model) View() string {
+	if m.showHelp {
+		return m.helpView()
+	}
+
 	switch m.screen {
 	case singleplayerScreen:
 		return m.game.View()
 	case multiplayerScreen:
 		return m.game.View()
+	case leaderboardScreen, settingsScreen:
+		return m.game.View()
 	case menuScreen:
 		return m.menu.View()
 	default:
 		return "Loading..."
 	}
 }
+
+// helpView lists every key that does something on the current screen
+func (m *model) helpView() string {
+	var keys help.KeyMap = m.common.Keys
+	if h, ok := m.active().(common.Helper); ok {
+		keys = h.KeyHelp()
+	}
+
+	r := m.common.Theme.Renderer
+	h := common.NewHelp(r)
+	h.ShowAll = true
+
+	box := r.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("241")).
+		Padding(1, 2).
+		Render(
+			r.NewStyle().Bold(true).Render("HELP") + "\n\n" +
+				h.View(keys) + "\n\n" +
+				h.ShortHelpView([]key.Binding{key.NewBinding(
+					key.WithKeys(append(m.common.Keys.Help.Keys(), m.common.Keys.Esc.Keys()...)...),
+					key.WithHelp(m.common.Keys.Help.Help().Key+"/"+m.common.Keys.Esc.Help().Key, "close"),
+				)}),
+		)
+
+	return lipgloss.Place(m.common.Width, m.common.Height, lipgloss.Center, lipgloss.Center, box)
+}